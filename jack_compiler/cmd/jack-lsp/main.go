@@ -0,0 +1,17 @@
+// jack-lsp is a language server of Jack speaking the Language Server Protocol over stdio.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/cou929/nand2tetris/jack_compiler/lsp"
+)
+
+func main() {
+	// stdout is the connection, so log to stderr
+	log.SetOutput(os.Stderr)
+	if err := lsp.NewServer(os.Stdin, os.Stdout).Run(); err != nil {
+		log.Fatal(err)
+	}
+}
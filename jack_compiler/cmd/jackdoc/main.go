@@ -0,0 +1,130 @@
+// jackdoc generates the API reference of Jack classes from their doc comments.
+// A page is written for each class along with an index of all classes, in HTML or Markdown.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/cou929/nand2tetris/jack_compiler/jack"
+)
+
+var (
+	outDir = "doc"
+	format = "html"
+)
+
+func main() {
+	flag.StringVar(&outDir, "o", "doc", "output directory")
+	flag.StringVar(&format, "format", "html", "output format (html|md)")
+	flag.Parse()
+
+	if format != "html" && format != "md" {
+		fmt.Fprintf(os.Stderr, "Invalid format %s want (html|md)\n", format)
+		os.Exit(2)
+	}
+	if flag.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "usage: jackdoc [-o dir] [-format html|md] path ...")
+		os.Exit(2)
+	}
+
+	var docs []*jack.ClassDoc
+	failed := false
+	for _, arg := range flag.Args() {
+		files, err := jackFiles(arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+			continue
+		}
+		for _, f := range files {
+			d, err := classDoc(f)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				failed = true
+				continue
+			}
+			docs = append(docs, d)
+		}
+	}
+	jack.SortClassDocs(docs)
+
+	if err := writeDocs(docs); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func classDoc(file string) (*jack.ClassDoc, error) {
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	tokenizer := jack.NewTokenizer(bytes.NewReader(src), file)
+	tokens, err := tokenizer.Tokenize()
+	if err != nil {
+		return nil, err
+	}
+	tree, err := jack.NewParser().Parse(tokens)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return jack.NewClassDoc(src, tree, tokenizer.Comments()), nil
+}
+
+func writeDocs(docs []*jack.ClassDoc) error {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+	for _, d := range docs {
+		page := d.Markdown()
+		if format == "html" {
+			var err error
+			if page, err = d.HTML(); err != nil {
+				return err
+			}
+		}
+		if err := ioutil.WriteFile(path.Join(outDir, d.Name+"."+format), []byte(page), 0644); err != nil {
+			return err
+		}
+	}
+
+	index := jack.DocIndexMarkdown(docs)
+	if format == "html" {
+		var err error
+		if index, err = jack.DocIndexHTML(docs); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(path.Join(outDir, "index."+format), []byte(index), 0644)
+}
+
+func jackFiles(p string) ([]string, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{p}, nil
+	}
+
+	files, err := ioutil.ReadDir(p)
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, f := range files {
+		if f.IsDir() || path.Ext(f.Name()) != ".jack" {
+			continue
+		}
+		res = append(res, path.Join(p, f.Name()))
+	}
+	return res, nil
+}
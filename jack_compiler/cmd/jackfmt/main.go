@@ -0,0 +1,131 @@
+// jackfmt formats Jack source files.
+// Without flags the formatted source is written to stdout. Directories are formatted for each .jack file in them.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+
+	"github.com/cou929/nand2tetris/jack_compiler/jack"
+)
+
+var (
+	write = false
+	diff  = false
+	ext   = false
+)
+
+func main() {
+	flag.BoolVar(&write, "w", false, "write result to the source file instead of stdout")
+	flag.BoolVar(&diff, "d", false, "display diffs instead of rewriting files")
+	flag.BoolVar(&ext, "ext", false, "read the sources with the language extensions: for, break, continue, else if, switch, const and enum")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "usage: jackfmt [-w] [-d] [-ext] path ...")
+		os.Exit(2)
+	}
+
+	failed := false
+	for _, arg := range flag.Args() {
+		files, err := jackFiles(arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+			continue
+		}
+		for _, f := range files {
+			if err := formatFile(f); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				failed = true
+			}
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func formatFile(file string) error {
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	res, err := jack.Format(src, file, ext)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	if diff {
+		if bytes.Equal(src, res) {
+			return nil
+		}
+		d, err := diffSource(file, src, res)
+		if err != nil {
+			return fmt.Errorf("%s: Failed to diff %w", file, err)
+		}
+		os.Stdout.Write(d)
+		return nil
+	}
+	if write {
+		if bytes.Equal(src, res) {
+			return nil
+		}
+		return ioutil.WriteFile(file, res, 0644)
+	}
+	_, err = os.Stdout.Write(res)
+	return err
+}
+
+// diffSource returns the unified diff between the source and the formatted one using diff command.
+func diffSource(file string, src, res []byte) ([]byte, error) {
+	dir, err := ioutil.TempDir("", "jackfmt")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	orig := path.Join(dir, "orig.jack")
+	formatted := path.Join(dir, "formatted.jack")
+	if err := ioutil.WriteFile(orig, src, 0644); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(formatted, res, 0644); err != nil {
+		return nil, err
+	}
+
+	out, err := exec.Command("diff", "-u", "--label", file+".orig", "--label", file, orig, formatted).Output()
+	if len(out) > 0 {
+		// diff exits with 1 when the files differ
+		return out, nil
+	}
+	return nil, err
+}
+
+func jackFiles(p string) ([]string, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{p}, nil
+	}
+
+	files, err := ioutil.ReadDir(p)
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, f := range files {
+		if f.IsDir() || path.Ext(f.Name()) != ".jack" {
+			continue
+		}
+		res = append(res, path.Join(p, f.Name()))
+	}
+	return res, nil
+}
@@ -0,0 +1,165 @@
+// jacklint reports problems of style and likely bugs in Jack source files.
+// The rules are configured by a JSON file such as {"rules": {"var-name": false}, "maxSubroutineLines": 80}.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/cou929/nand2tetris/jack_compiler/jack"
+)
+
+const defaultConfig = ".jacklint.json"
+
+var (
+	configPath = defaultConfig
+	format     = "text"
+	listRules  = false
+)
+
+// issue is a lint issue in the JSON output.
+type issue struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func main() {
+	flag.StringVar(&configPath, "config", defaultConfig, "config file, ignored if the default one does not exist")
+	flag.StringVar(&format, "format", "text", "output format (text|json)")
+	flag.BoolVar(&listRules, "rules", false, "list the rules and exit")
+	flag.Parse()
+
+	if listRules {
+		for _, r := range jack.LintRules {
+			fmt.Printf("%-16s %s\n", r.Name, r.Doc)
+		}
+		return
+	}
+	if format != "text" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Invalid format %s want (text|json)\n", format)
+		os.Exit(2)
+	}
+	if flag.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "usage: jacklint [-config file] [-format text|json] path ...")
+		os.Exit(2)
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	issues := []issue{}
+	failed := false
+	for _, arg := range flag.Args() {
+		files, err := jackFiles(arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+			continue
+		}
+		for _, f := range files {
+			res, err := lintFile(f, config)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				failed = true
+				continue
+			}
+			issues = append(issues, res...)
+		}
+	}
+
+	if format == "json" {
+		b, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		fmt.Println(string(b))
+	} else {
+		for _, i := range issues {
+			fmt.Printf("%s:%d:%d: %s (%s)\n", i.File, i.Line, i.Column, i.Message, i.Rule)
+		}
+	}
+
+	if failed {
+		os.Exit(2)
+	}
+	if len(issues) > 0 {
+		os.Exit(1)
+	}
+}
+
+func loadConfig(p string) (*jack.LintConfig, error) {
+	b, err := ioutil.ReadFile(p)
+	if os.IsNotExist(err) && p == defaultConfig {
+		return jack.DefaultLintConfig(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	config, err := jack.ParseLintConfig(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	return config, nil
+}
+
+func lintFile(file string, config *jack.LintConfig) ([]issue, error) {
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := jack.NewTokenizer(bytes.NewReader(src), file).Tokenize()
+	if err != nil {
+		return nil, err
+	}
+	tree, err := jack.NewParser().Parse(tokens)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	var res []issue
+	for _, i := range jack.Lint(tree, config) {
+		res = append(res, issue{
+			File:    file,
+			Line:    i.Pos.Line,
+			Column:  i.Pos.Column,
+			Rule:    i.Rule,
+			Message: i.Msg,
+		})
+	}
+	return res, nil
+}
+
+func jackFiles(p string) ([]string, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{p}, nil
+	}
+
+	files, err := ioutil.ReadDir(p)
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, f := range files {
+		if f.IsDir() || path.Ext(f.Name()) != ".jack" {
+			continue
+		}
+		res = append(res, path.Join(p, f.Name()))
+	}
+	return res, nil
+}
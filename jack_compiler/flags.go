@@ -0,0 +1,113 @@
+package main
+
+import "fmt"
+
+// checkFlag is the mode of an optional check such as -typecheck and -flow.
+// "-typecheck" reports the problems as warnings and "-typecheck=error" as errors.
+type checkFlag int
+
+const (
+	checkOff checkFlag = iota
+	checkWarn
+	checkError
+)
+
+func (f *checkFlag) String() string {
+	switch *f {
+	case checkWarn:
+		return "warn"
+	case checkError:
+		return "error"
+	}
+	return "off"
+}
+
+func (f *checkFlag) Set(s string) error {
+	switch s {
+	case "false", "off":
+		*f = checkOff
+	case "true", "warn":
+		*f = checkWarn
+	case "error":
+		*f = checkError
+	default:
+		return fmt.Errorf("Invalid check mode %s want (warn|error|off)", s)
+	}
+	return nil
+}
+
+func (f *checkFlag) IsBoolFlag() bool {
+	return true
+}
+
+// precedenceFlag is the order of binary ops. By default they are applied left to right as the Jack spec states,
+// "-precedence" applies them by conventional precedence and "-precedence=warn" reports expressions whose meaning differs.
+type precedenceFlag int
+
+const (
+	precedenceOff precedenceFlag = iota
+	precedenceWarn
+	precedenceOn
+)
+
+func (f *precedenceFlag) String() string {
+	switch *f {
+	case precedenceWarn:
+		return "warn"
+	case precedenceOn:
+		return "on"
+	}
+	return "off"
+}
+
+func (f *precedenceFlag) Set(s string) error {
+	switch s {
+	case "false", "off":
+		*f = precedenceOff
+	case "true", "on":
+		*f = precedenceOn
+	case "warn":
+		*f = precedenceWarn
+	default:
+		return fmt.Errorf("Invalid precedence mode %s want (on|warn|off)", s)
+	}
+	return nil
+}
+
+func (f *precedenceFlag) IsBoolFlag() bool {
+	return true
+}
+
+// astFlag is the format of -ast output.
+type astFlag string
+
+func (f *astFlag) String() string {
+	return string(*f)
+}
+
+func (f *astFlag) Set(s string) error {
+	switch s {
+	case "json", "sexpr":
+		*f = astFlag(s)
+	default:
+		return fmt.Errorf("Invalid ast format %s want (json|sexpr)", s)
+	}
+	return nil
+}
+
+// dotFlag is the graph of -dot output.
+type dotFlag string
+
+func (f *dotFlag) String() string {
+	return string(*f)
+}
+
+func (f *dotFlag) Set(s string) error {
+	switch s {
+	case "tree", "callgraph":
+		*f = dotFlag(s)
+	default:
+		return fmt.Errorf("Invalid dot graph %s want (tree|callgraph)", s)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package jack
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AST is the parse tree of a file in the form to be encoded to JSON or S-expression.
+// Unlike the xml of the parse tree, it keeps node types, source ranges and symbol info of identifiers.
+type AST struct {
+	File string   `json:"file"`
+	Root *ASTNode `json:"root"`
+}
+
+// ASTNode is a node of AST. Value is set to leaf nodes only, and Range is nil for empty nodes such as an empty parameter list.
+type ASTNode struct {
+	Type     string       `json:"type"`
+	Value    string       `json:"value,omitempty"`
+	Range    *SourceRange `json:"range,omitempty"`
+	Meta     *ASTMeta     `json:"meta,omitempty"`
+	Children []*ASTNode   `json:"children,omitempty"`
+}
+
+// SourceRange is the range of a node in the source file. End is the position just after the node.
+type SourceRange struct {
+	Start SourcePoint `json:"start"`
+	End   SourcePoint `json:"end"`
+}
+
+type SourcePoint struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+// ASTMeta is IDMeta of an identifier. Kind, Type and Index are set to variables only.
+type ASTMeta struct {
+	Category    string `json:"category"`
+	Declaration bool   `json:"declaration"`
+	Kind        string `json:"kind,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Index       *int   `json:"index,omitempty"`
+}
+
+func NewAST(file string, tree TreeNode) *AST {
+	return &AST{
+		File: file,
+		Root: newASTNode(tree),
+	}
+}
+
+func newASTNode(n TreeNode) *ASTNode {
+	res := &ASTNode{
+		Type: n.Type().String(),
+	}
+	if start, end := n.Pos(), n.End(); start.IsValid() && end.IsValid() {
+		res.Range = &SourceRange{
+			Start: SourcePoint{start.Line, start.Column, start.Offset},
+			End:   SourcePoint{end.Line, end.Column, end.Offset},
+		}
+	}
+	if _, ok := n.(*LeafNode); ok {
+		res.Value = n.Value()
+		if meta := n.Meta(); meta != nil {
+			res.Meta = newASTMeta(meta)
+		}
+	}
+	for _, c := range n.ChildNodes() {
+		res.Children = append(res.Children, newASTNode(c))
+	}
+	return res
+}
+
+func newASTMeta(meta *IDMeta) *ASTMeta {
+	res := &ASTMeta{
+		Category:    meta.Category.String(),
+		Declaration: meta.Declaration,
+	}
+	if s := meta.SymbolInfo; s != nil {
+		index := s.Index
+		res.Kind = s.Kind.String()
+		res.Type = s.Type
+		res.Index = &index
+	}
+	return res
+}
+
+func (a *AST) JSON() (string, error) {
+	b, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("[AST.JSON] %w", err)
+	}
+	return string(b), nil
+}
+
+// Sexpr returns the S-expression of the AST, (ast "file" node).
+// Each node is (Type "value"? (range (line column offset) (line column offset))? (meta ...)? children...).
+func (a *AST) Sexpr() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "(ast %s\n", strconv.Quote(a.File))
+	a.Root.sexpr(&b, 1)
+	b.WriteString(")")
+	return b.String()
+}
+
+func (n *ASTNode) sexpr(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString("(" + n.Type)
+	if n.Value != "" {
+		b.WriteString(" " + strconv.Quote(n.Value))
+	}
+	if r := n.Range; r != nil {
+		fmt.Fprintf(b, " (range (%d %d %d) (%d %d %d))",
+			r.Start.Line, r.Start.Column, r.Start.Offset, r.End.Line, r.End.Column, r.End.Offset)
+	}
+	if m := n.Meta; m != nil {
+		fmt.Fprintf(b, " (meta (category %s) (declaration %t)", m.Category, m.Declaration)
+		if m.Index != nil {
+			fmt.Fprintf(b, " (kind %s) (type %s) (index %d)", m.Kind, strconv.Quote(m.Type), *m.Index)
+		}
+		b.WriteString(")")
+	}
+	for _, c := range n.Children {
+		b.WriteString("\n")
+		c.sexpr(b, depth+1)
+	}
+	b.WriteString(")")
+}
@@ -0,0 +1,89 @@
+package jack
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// parseForTest parses src as Main.jack, with the language extensions if ext is true.
+func parseForTest(t *testing.T, src string, ext bool) *InnerNode {
+	t.Helper()
+	tokenizer := NewTokenizer(strings.NewReader(src), "Main.jack")
+	tokenizer.SetExtensions(ext)
+	tokens, err := tokenizer.Tokenize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser := NewParser()
+	parser.SetExtensions(ext)
+	tree, err := parser.Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+func TestAST_Sexpr(t *testing.T) {
+	tree := parseForTest(t, "class Main {\n  static String s;\n}", false)
+	want := `(ast "Main.jack"
+  (ClassType (range (1 1 0) (3 2 33))
+    (KeywordType "class" (range (1 1 0) (1 6 5)))
+    (ClassNameType (range (1 7 6) (1 11 10))
+      (IdentifierType "Main" (range (1 7 6) (1 11 10)) (meta (category IdCatClass) (declaration true))))
+    (SymbolType "{" (range (1 12 11) (1 13 12)))
+    (ClassVarDecType (range (2 3 15) (2 19 31))
+      (KeywordType "static" (range (2 3 15) (2 9 21)))
+      (TypeType (range (2 10 22) (2 16 28))
+        (ClassNameType (range (2 10 22) (2 16 28))
+          (IdentifierType "String" (range (2 10 22) (2 16 28)) (meta (category IdCatClass) (declaration false)))))
+      (VarNameType (range (2 17 29) (2 18 30))
+        (IdentifierType "s" (range (2 17 29) (2 18 30)) (meta (category IdCatStatic) (declaration true) (kind Static) (type "String") (index 0))))
+      (SymbolType ";" (range (2 18 30) (2 19 31))))
+    (SymbolType "}" (range (3 1 32) (3 2 33)))))`
+	got := NewAST("Main.jack", tree).Sexpr()
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("AST.Sexpr() diff (-got +want)\n%s", diff)
+	}
+}
+
+func TestAST_JSON(t *testing.T) {
+	tree := parseForTest(t, "class Main {\n  function void f() { var int x; return; }\n}", false)
+	got, err := NewAST("Main.jack", tree).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a AST
+	if err := json.Unmarshal([]byte(got), &a); err != nil {
+		t.Fatal(err)
+	}
+
+	sub := a.Root.Children[3]
+	params := sub.Children[4]
+	if params.Type != "ParameterListType" || params.Range != nil || params.Children != nil {
+		t.Errorf("empty parameter list = %+v, want no range and children", params)
+	}
+
+	varName := sub.Children[6].Children[1].Children[2].Children[0]
+	index := 0
+	want := &ASTNode{
+		Type:  "IdentifierType",
+		Value: "x",
+		Range: &SourceRange{
+			Start: SourcePoint{2, 31, 43},
+			End:   SourcePoint{2, 32, 44},
+		},
+		Meta: &ASTMeta{
+			Category:    "IdCatVar",
+			Declaration: true,
+			Kind:        "Var",
+			Type:        "int",
+			Index:       &index,
+		},
+	}
+	if diff := cmp.Diff(varName, want); diff != "" {
+		t.Errorf("AST.JSON() diff (-got +want)\n%s", diff)
+	}
+}
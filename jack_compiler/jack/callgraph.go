@@ -0,0 +1,186 @@
+package jack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CallGraph is the graph of subroutine calls of a program. Nodes are full names of subroutines such as Main.main.
+type CallGraph struct {
+	subs     []string // subroutines defined in the program, in order of definition
+	defined  map[string]bool
+	calls    map[string][]string // callees of each caller, in order of first call
+	implicit map[[2]string]bool  // calls generated by the compiler, such as Memory.alloc in constructors
+}
+
+func NewCallGraph() *CallGraph {
+	return &CallGraph{
+		defined:  make(map[string]bool),
+		calls:    make(map[string][]string),
+		implicit: make(map[[2]string]bool),
+	}
+}
+
+func (g *CallGraph) AddSubroutine(name string) {
+	if g.defined[name] {
+		return
+	}
+	g.defined[name] = true
+	g.subs = append(g.subs, name)
+}
+
+// AddCall adds the edge from caller to callee. An implicit call is not written in the source.
+func (g *CallGraph) AddCall(caller, callee string, implicit bool) {
+	for _, c := range g.calls[caller] {
+		if c == callee {
+			if !implicit {
+				delete(g.implicit, [2]string{caller, callee})
+			}
+			return
+		}
+	}
+	g.calls[caller] = append(g.calls[caller], callee)
+	if implicit {
+		g.implicit[[2]string{caller, callee}] = true
+	}
+}
+
+// Recursive returns the subroutines which can call themselves directly or indirectly.
+func (g *CallGraph) Recursive() map[string]bool {
+	res := make(map[string]bool)
+	for _, n := range g.nodes() {
+		for _, m := range g.calls[n] {
+			if g.reaches(m, n) {
+				res[n] = true
+			}
+		}
+	}
+	return res
+}
+
+// Unreachable returns the subroutines defined in the program which are not called from entry directly or indirectly.
+func (g *CallGraph) Unreachable(entry string) []string {
+	reached := map[string]bool{entry: true}
+	queue := []string{entry}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, m := range g.calls[n] {
+			if !reached[m] {
+				reached[m] = true
+				queue = append(queue, m)
+			}
+		}
+	}
+
+	var res []string
+	for _, n := range g.subs {
+		if !reached[n] {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
+// Dot returns the graph in Graphviz dot language. Recursive subroutines and calls are red, and subroutines unreachable from
+// Main.main are gray. Subroutines not defined in the program, such as OS functions, are grouped by class.
+func (g *CallGraph) Dot() string {
+	recursive := g.Recursive()
+	unreachable := make(map[string]bool)
+	if g.defined["Main.main"] {
+		for _, n := range g.Unreachable("Main.main") {
+			unreachable[n] = true
+		}
+	}
+
+	res := []string{
+		"digraph callgraph {",
+		"  node [shape=box];",
+	}
+	for _, n := range g.subs {
+		var attrs []string
+		if recursive[n] {
+			attrs = append(attrs, "color=red")
+		}
+		if unreachable[n] {
+			attrs = append(attrs, "style=dashed", "fontcolor=gray")
+		}
+		res = append(res, fmt.Sprintf("  %s%s;", dotQuote(n), dotAttrs(attrs)))
+	}
+
+	external := make(map[string][]string)
+	var classes []string
+	for _, n := range g.nodes() {
+		if g.defined[n] {
+			continue
+		}
+		class := strings.Split(n, ".")[0]
+		if _, ok := external[class]; !ok {
+			classes = append(classes, class)
+		}
+		external[class] = append(external[class], n)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		res = append(res, fmt.Sprintf("  subgraph %s {", dotQuote("cluster_"+class)))
+		res = append(res, fmt.Sprintf("    label=%s;", dotQuote(class)))
+		for _, n := range external[class] {
+			res = append(res, fmt.Sprintf("    %s [style=filled, fillcolor=lightgray];", dotQuote(n)))
+		}
+		res = append(res, "  }")
+	}
+
+	for _, caller := range g.nodes() {
+		for _, callee := range g.calls[caller] {
+			var attrs []string
+			if g.reaches(callee, caller) {
+				attrs = append(attrs, "color=red")
+			}
+			if g.implicit[[2]string{caller, callee}] {
+				attrs = append(attrs, "style=dashed")
+			}
+			res = append(res, fmt.Sprintf("  %s -> %s%s;", dotQuote(caller), dotQuote(callee), dotAttrs(attrs)))
+		}
+	}
+	res = append(res, "}")
+	return strings.Join(res, "\n")
+}
+
+// nodes returns the defined subroutines followed by the other callees in order of appearance.
+func (g *CallGraph) nodes() []string {
+	res := append([]string{}, g.subs...)
+	seen := make(map[string]bool)
+	for _, n := range g.subs {
+		seen[n] = true
+	}
+	for i := 0; i < len(res); i++ {
+		for _, m := range g.calls[res[i]] {
+			if !seen[m] {
+				seen[m] = true
+				res = append(res, m)
+			}
+		}
+	}
+	return res
+}
+
+// reaches reports whether from calls to directly or indirectly.
+func (g *CallGraph) reaches(from, to string) bool {
+	seen := map[string]bool{from: true}
+	queue := []string{from}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n == to {
+			return true
+		}
+		for _, m := range g.calls[n] {
+			if !seen[m] {
+				seen[m] = true
+				queue = append(queue, m)
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package jack
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCallGraph(t *testing.T) {
+	src := `class Main {
+  function void main() { do Main.fib(10); return; }
+  function int fib(int n) { if (n < 2) { return n; } return Main.fib(n - 1) + Main.fib(n - 2); }
+  function void even(int n) { do Main.odd(n); return; }
+  function void odd(int n) { do Main.even(n); return; }
+  function void greet() { do Output.printString("hi"); return; }
+}`
+	g := NewCallGraph()
+	c := NewCompiler()
+	c.SetCallGraph(g)
+	if _, err := c.Compile(parseForTest(t, src, false)); err != nil {
+		t.Fatal(err)
+	}
+
+	wantRecursive := map[string]bool{"Main.fib": true, "Main.even": true, "Main.odd": true}
+	if diff := cmp.Diff(g.Recursive(), wantRecursive); diff != "" {
+		t.Errorf("CallGraph.Recursive() diff (-got +want)\n%s", diff)
+	}
+
+	wantUnreachable := []string{"Main.even", "Main.odd", "Main.greet"}
+	if diff := cmp.Diff(g.Unreachable("Main.main"), wantUnreachable); diff != "" {
+		t.Errorf("CallGraph.Unreachable() diff (-got +want)\n%s", diff)
+	}
+
+	want := `digraph callgraph {
+  node [shape=box];
+  "Main.main";
+  "Main.fib" [color=red];
+  "Main.even" [color=red, style=dashed, fontcolor=gray];
+  "Main.odd" [color=red, style=dashed, fontcolor=gray];
+  "Main.greet" [style=dashed, fontcolor=gray];
+  subgraph "cluster_Output" {
+    label="Output";
+    "Output.printString" [style=filled, fillcolor=lightgray];
+  }
+  subgraph "cluster_String" {
+    label="String";
+    "String.new" [style=filled, fillcolor=lightgray];
+    "String.appendChar" [style=filled, fillcolor=lightgray];
+  }
+  "Main.main" -> "Main.fib";
+  "Main.fib" -> "Main.fib" [color=red];
+  "Main.even" -> "Main.odd" [color=red];
+  "Main.odd" -> "Main.even" [color=red];
+  "Main.greet" -> "String.new" [style=dashed];
+  "Main.greet" -> "String.appendChar" [style=dashed];
+  "Main.greet" -> "Output.printString";
+}`
+	if diff := cmp.Diff(g.Dot(), want); diff != "" {
+		t.Errorf("CallGraph.Dot() diff (-got +want)\n%s", diff)
+	}
+}
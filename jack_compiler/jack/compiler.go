@@ -1,4 +1,4 @@
-package main
+package jack
 
 import (
 	"fmt"
@@ -7,11 +7,25 @@ import (
 )
 
 type Compiler struct {
-	curClassInfo *classInfo
-	curFuncInfo  *funcInfo
-	ifCounter    int
-	whileCounter int
-	vmc          *VmCode
+	curClassInfo  *classInfo
+	curFuncInfo   *funcInfo
+	ifCounter     int
+	whileCounter  int
+	forCounter    int
+	switchCounter int
+	loops         []loopLabels // enclosing loops, innermost last
+	vmc           *VmCode
+	graph         *CallGraph
+	precedence    bool
+	optimize      bool
+	shortCircuit  bool
+	constants     map[string]int
+}
+
+// loopLabels is the labels which continue and break jump to in a loop.
+type loopLabels struct {
+	cont string
+	end  string
 }
 
 type classInfo struct {
@@ -45,12 +59,50 @@ func (f funcKind) String() string {
 	return "Invalid funcKind"
 }
 
+func newFuncKind(in string) funcKind {
+	switch in {
+	case "constructor":
+		return Constructor
+	case "function":
+		return Function
+	case "method":
+		return Method
+	}
+	return 0
+}
+
 func NewCompiler() *Compiler {
 	return &Compiler{
 		vmc: NewVmCode(),
 	}
 }
 
+// SetCallGraph makes the compiler record the subroutines and the calls it compiles to g.
+func (c *Compiler) SetCallGraph(g *CallGraph) {
+	c.graph = g
+}
+
+// SetPrecedence makes the compiler apply binary ops by conventional precedence instead of left to right.
+func (c *Compiler) SetPrecedence(on bool) {
+	c.precedence = on
+}
+
+// SetOptimize makes the compiler fold constants and simplify arithmetic in expressions.
+func (c *Compiler) SetOptimize(on bool) {
+	c.optimize = on
+}
+
+// SetShortCircuit makes the compiler skip the right operand of & and | in conditions if the left one decides the result.
+func (c *Compiler) SetShortCircuit(on bool) {
+	c.shortCircuit = on
+}
+
+// SetConstants gives the values of the constants of other classes, keyed by Class.NAME, to the compiler.
+// The constants of the class being compiled are resolved by the parser.
+func (c *Compiler) SetConstants(constants map[string]int) {
+	c.constants = constants
+}
+
 func (c *Compiler) Compile(pt TreeNode) (string, error) {
 	codes, err := c.compile(pt)
 	if err != nil {
@@ -85,6 +137,12 @@ func (c *Compiler) compile(pt TreeNode) ([]string, error) {
 		return c.compileIfStatement(pt)
 	case WhileStatementType:
 		return c.compileWhileStatement(pt)
+	case ForStatementType:
+		return c.compileForStatement(pt)
+	case BreakStatementType, ContinueStatementType:
+		return c.compileLoopJump(pt)
+	case SwitchStatementType:
+		return c.compileSwitchStatement(pt)
 	case DoStatementType:
 		return c.compileDoStatement(pt)
 	case ReturnStatementType:
@@ -108,6 +166,8 @@ func (c *Compiler) resetAllState() {
 	c.resetFuncState()
 	c.resetIfCounter()
 	c.resetWhileCounter()
+	c.resetForCounter()
+	c.resetSwitchCounter()
 }
 
 func (c *Compiler) resetClassState() {
@@ -154,6 +214,15 @@ func (c *Compiler) setFuncKind(in string) error {
 	return fmt.Errorf("Invalid function kind %s", in)
 }
 
+// addCall records the call from the current subroutine to the call graph if set.
+func (c *Compiler) addCall(className string, subName string, implicit bool) {
+	if c.graph == nil {
+		return
+	}
+	caller := fmt.Sprintf("%s.%s", c.curClassInfo.name, c.curFuncInfo.name)
+	c.graph.AddCall(caller, fmt.Sprintf("%s.%s", className, subName), implicit)
+}
+
 func (c *Compiler) incLocalVarCount() {
 	if c.curFuncInfo == nil {
 		c.curFuncInfo = &funcInfo{}
@@ -177,6 +246,22 @@ func (c *Compiler) incWhileCounter() {
 	c.whileCounter++
 }
 
+func (c *Compiler) resetForCounter() {
+	c.forCounter = 0
+}
+
+func (c *Compiler) incForCounter() {
+	c.forCounter++
+}
+
+func (c *Compiler) resetSwitchCounter() {
+	c.switchCounter = 0
+}
+
+func (c *Compiler) incSwitchCounter() {
+	c.switchCounter++
+}
+
 func (c *Compiler) compileClass(pt TreeNode) ([]string, error) {
 	c.resetAllState()
 
@@ -245,6 +330,10 @@ func (c *Compiler) compileSubroutineDec(pt TreeNode) ([]string, error) {
 		res = append(res, codes...)
 	}
 
+	if c.graph != nil {
+		c.graph.AddSubroutine(fmt.Sprintf("%s.%s", c.curClassInfo.name, c.curFuncInfo.name))
+	}
+
 	// prepare this segment
 	switch c.curFuncInfo.kind {
 	case Method:
@@ -263,6 +352,7 @@ func (c *Compiler) compileSubroutineDec(pt TreeNode) ([]string, error) {
 			c.vmc.pop("pointer", 0),
 		}
 		res = append(codes, res...) // prepend
+		c.addCall("Memory", "alloc", true)
 	}
 
 	// prepend function declaration
@@ -335,7 +425,7 @@ func (c *Compiler) compileLetStatement(pt TreeNode) ([]string, error) {
 	varName := pt.ChildNodes()[1]
 
 	// case of array
-	if len(pt.ChildNodes()) == 8 {
+	if isArrayLet(pt) {
 		exp := pt.ChildNodes()[6]
 		codes, err := c.compile(exp)
 		if err != nil {
@@ -403,15 +493,13 @@ func (c *Compiler) compileIfStatement(pt TreeNode) ([]string, error) {
 	elseLabel := fmt.Sprintf("%s.%s.%d.IF.ELSE", c.curClassInfo.name, c.curFuncInfo.name, suffix)
 
 	// ~(cond)
-	cond, err := c.compile(pt.ChildNodes()[2])
+	cond, err := c.compileCondition(pt.ChildNodes()[2], elseLabel)
 	if err != nil {
 		return nil, fmt.Errorf("[compileIfStatement] %w", err)
 	}
-	condNot := append(cond, c.vmc.not())
-	res = append(res, condNot...)
+	res = append(res, cond...)
 
 	// if statement
-	res = append(res, c.vmc.ifGoTo(elseLabel))
 	ifStatement, err := c.compile(pt.ChildNodes()[5])
 	if err != nil {
 		return nil, fmt.Errorf("[compileIfStatement] %w", err)
@@ -421,8 +509,8 @@ func (c *Compiler) compileIfStatement(pt TreeNode) ([]string, error) {
 
 	// else statement
 	res = append(res, c.vmc.label(elseLabel))
-	if len(pt.ChildNodes()) > 10 {
-		elseStatement, err := c.compile(pt.ChildNodes()[9])
+	if e := elseBranch(pt); e != nil {
+		elseStatement, err := c.compile(e)
 		if err != nil {
 			return nil, fmt.Errorf("[compileIfStatement] %w", err)
 		}
@@ -443,16 +531,16 @@ func (c *Compiler) compileWhileStatement(pt TreeNode) ([]string, error) {
 	res = append(res, c.vmc.label(contLabel))
 
 	// ~(cond)
-	cond, err := c.compile(pt.ChildNodes()[2])
+	cond, err := c.compileCondition(pt.ChildNodes()[2], endLabel)
 	if err != nil {
 		return nil, fmt.Errorf("[compileWhileStatement] %w", err)
 	}
-	condNot := append(cond, c.vmc.not())
-	res = append(res, condNot...)
-	res = append(res, c.vmc.ifGoTo(endLabel))
+	res = append(res, cond...)
 
 	// statement
+	c.loops = append(c.loops, loopLabels{cont: contLabel, end: endLabel})
 	stmt, err := c.compile(pt.ChildNodes()[5])
+	c.loops = c.loops[:len(c.loops)-1]
 	if err != nil {
 		return nil, fmt.Errorf("[compileWhileStatement] %w", err)
 	}
@@ -463,6 +551,115 @@ func (c *Compiler) compileWhileStatement(pt TreeNode) ([]string, error) {
 	return res, nil
 }
 
+func (c *Compiler) compileForStatement(pt TreeNode) ([]string, error) {
+	var res []string
+	suffix := c.forCounter
+	c.incForCounter()
+	condLabel := fmt.Sprintf("%s.%s.%d.FOR.COND", c.curClassInfo.name, c.curFuncInfo.name, suffix)
+	contLabel := fmt.Sprintf("%s.%s.%d.FOR.CONT", c.curClassInfo.name, c.curFuncInfo.name, suffix)
+	endLabel := fmt.Sprintf("%s.%s.%d.FOR.END", c.curClassInfo.name, c.curFuncInfo.name, suffix)
+
+	// initialization
+	init, err := c.compile(pt.ChildNodes()[2])
+	if err != nil {
+		return nil, fmt.Errorf("[compileForStatement] %w", err)
+	}
+	res = append(res, init...)
+	res = append(res, c.vmc.label(condLabel))
+
+	// ~(cond)
+	cond, err := c.compileCondition(pt.ChildNodes()[3], endLabel)
+	if err != nil {
+		return nil, fmt.Errorf("[compileForStatement] %w", err)
+	}
+	res = append(res, cond...)
+
+	// statement
+	c.loops = append(c.loops, loopLabels{cont: contLabel, end: endLabel})
+	stmt, err := c.compile(pt.ChildNodes()[8])
+	c.loops = c.loops[:len(c.loops)-1]
+	if err != nil {
+		return nil, fmt.Errorf("[compileForStatement] %w", err)
+	}
+	res = append(res, stmt...)
+
+	// update
+	res = append(res, c.vmc.label(contLabel))
+	update, err := c.compile(pt.ChildNodes()[5])
+	if err != nil {
+		return nil, fmt.Errorf("[compileForStatement] %w", err)
+	}
+	res = append(res, update...)
+	res = append(res, c.vmc.goTo(condLabel))
+	res = append(res, c.vmc.label(endLabel))
+
+	return res, nil
+}
+
+// switchTemp is the temp register holding the value switched on while it is compared with the cases.
+const switchTemp = 0
+
+// compileSwitchStatement compiles a chain comparing the value with each case and jumping to its statements.
+func (c *Compiler) compileSwitchStatement(pt TreeNode) ([]string, error) {
+	var res []string
+	suffix := c.switchCounter
+	c.incSwitchCounter()
+	prefix := fmt.Sprintf("%s.%s.%d.SWITCH", c.curClassInfo.name, c.curFuncInfo.name, suffix)
+	endLabel := prefix + ".END"
+
+	children := pt.ChildNodes()
+	value, err := c.compile(children[2])
+	if err != nil {
+		return nil, fmt.Errorf("[compileSwitchStatement] %w", err)
+	}
+	res = append(res, value...)
+	res = append(res, c.vmc.pop("temp", switchTemp))
+
+	var bodies []string
+	defaultLabel := endLabel
+	for i, sc := range children[5 : len(children)-1] {
+		caseChildren := sc.ChildNodes()
+		label := fmt.Sprintf("%s.CASE.%d", prefix, i)
+		if caseChildren[0].Value() == "default" {
+			label = prefix + ".DEFAULT"
+			defaultLabel = label
+		} else {
+			v, err := c.compileTerm(caseChildren[1])
+			if err != nil {
+				return nil, fmt.Errorf("[compileSwitchStatement] %w", err)
+			}
+			res = append(res, c.vmc.push("temp", switchTemp))
+			res = append(res, v.code...)
+			res = append(res, c.vmc.eq(), c.vmc.ifGoTo(label))
+		}
+
+		stmt, err := c.compile(caseChildren[len(caseChildren)-2])
+		if err != nil {
+			return nil, fmt.Errorf("[compileSwitchStatement] %w", err)
+		}
+		bodies = append(bodies, c.vmc.label(label))
+		bodies = append(bodies, stmt...)
+		bodies = append(bodies, c.vmc.goTo(endLabel))
+	}
+	res = append(res, c.vmc.goTo(defaultLabel))
+	res = append(res, bodies...)
+	res = append(res, c.vmc.label(endLabel))
+
+	return res, nil
+}
+
+// compileLoopJump compiles break and continue to jump to the end or the next iteration of the innermost loop.
+func (c *Compiler) compileLoopJump(pt TreeNode) ([]string, error) {
+	if len(c.loops) == 0 {
+		return nil, fmt.Errorf("[compileLoopJump] %s outside a loop", pt.ChildNodes()[0].Value())
+	}
+	loop := c.loops[len(c.loops)-1]
+	if pt.Type() == BreakStatementType {
+		return []string{c.vmc.goTo(loop.end)}, nil
+	}
+	return []string{c.vmc.goTo(loop.cont)}, nil
+}
+
 func (c *Compiler) compileDoStatement(pt TreeNode) ([]string, error) {
 	var res []string
 	for _, node := range pt.ChildNodes() {
@@ -502,116 +699,169 @@ func (c *Compiler) compileExpression(pt TreeNode) ([]string, error) {
 }
 
 func (c *Compiler) traverseExpression(exps []TreeNode) ([]string, error) {
-	var res []string
+	o, err := c.compileOperand(exps)
+	if err != nil {
+		return nil, err
+	}
+	return o.code, nil
+}
 
+// compileOperand compiles an expression, exps is term (op term)*.
+func (c *Compiler) compileOperand(exps []TreeNode) (*operand, error) {
 	if len(exps) == 1 {
-		term := exps[0]
-		child := term.ChildNodes()[0]
-		switch child.Type() {
-		case IntConstType:
-			i, err := strconv.Atoi(child.Value())
-			if err != nil {
-				return nil, fmt.Errorf("[compileExpression] %w", err)
-			}
-			res = append(res, c.vmc.pushConstant(i))
-		case VarNameType:
-			switch child.Meta().Category {
-			case IdCatStatic:
-				res = append(res, c.vmc.push("static", child.Meta().SymbolInfo.Index))
-			case IdCatField:
-				res = append(res, c.vmc.push("this", child.Meta().SymbolInfo.Index))
-			case IdCatArg:
-				idx := child.Meta().SymbolInfo.Index
-				if c.curFuncInfo.kind == Method {
-					idx++
-				}
-				res = append(res, c.vmc.push("argument", idx))
-			case IdCatVar:
-				res = append(res, c.vmc.push("local", child.Meta().SymbolInfo.Index))
-			}
-			if len(term.ChildNodes()) == 4 {
-				// case of array
-				idxExp := term.ChildNodes()[2]
-				idx, err := c.compile(idxExp)
-				if err != nil {
-					return nil, fmt.Errorf("[compileExpression] %w", err)
-				}
-				res = append(res, idx...)
-				res = append(res, c.vmc.add())
-				res = append(res, c.vmc.pop("pointer", 1))
-				res = append(res, c.vmc.push("that", 0))
-			}
-		case SubroutineCallType:
-			codes, err := c.compile(child)
-			if err != nil {
-				return nil, fmt.Errorf("[compileExpression] %w", err)
-			}
-			res = append(res, codes...)
-		case SymbolType: // (expression)
-			exp := term.ChildNodes()[1]
-			codes, err := c.compile(exp)
-			if err != nil {
+		return c.compileTerm(exps[0])
+	}
+
+	// Pending ops are applied once an op binding no tighter follows,
+	// so with equal precedence everything is evaluated left to right.
+	o, err := c.compileTerm(exps[0])
+	if err != nil {
+		return nil, fmt.Errorf("[compileExpression] %w", err)
+	}
+	operands := []*operand{o}
+	var ops []TreeNode
+	apply := func() error {
+		n := len(operands)
+		o, err := c.binaryOperand(ops[len(ops)-1], operands[n-2], operands[n-1])
+		if err != nil {
+			return err
+		}
+		operands = append(operands[:n-2], o)
+		ops = ops[:len(ops)-1]
+		return nil
+	}
+	for i := 1; i+1 < len(exps); i += 2 {
+		for len(ops) > 0 && c.opPrecedence(ops[len(ops)-1]) >= c.opPrecedence(exps[i]) {
+			if err := apply(); err != nil {
 				return nil, fmt.Errorf("[compileExpression] %w", err)
 			}
-			res = append(res, codes...)
-		case UnaryOpType:
-			op := term.ChildNodes()[0]
-			term := term.ChildNodes()[1]
-			codes, err := c.traverseExpression([]TreeNode{term})
-			if err != nil {
-				return nil, fmt.Errorf("[compileExpression] %w", err)
+		}
+		ops = append(ops, exps[i])
+
+		o, err := c.compileTerm(exps[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("[compileExpression] %w", err)
+		}
+		operands = append(operands, o)
+	}
+	for len(ops) > 0 {
+		if err := apply(); err != nil {
+			return nil, fmt.Errorf("[compileExpression] %w", err)
+		}
+	}
+	return operands[0], nil
+}
+
+func (c *Compiler) compileTerm(term TreeNode) (*operand, error) {
+	var res []string
+	child := term.ChildNodes()[0]
+	switch child.Type() {
+	case IntConstType:
+		i, err := strconv.Atoi(child.Value())
+		if err != nil {
+			return nil, fmt.Errorf("[compileExpression] %w", err)
+		}
+		return c.constOperand(i), nil
+	case VarNameType:
+		switch child.Meta().Category {
+		case IdCatStatic:
+			res = append(res, c.vmc.push("static", child.Meta().SymbolInfo.Index))
+		case IdCatField:
+			res = append(res, c.vmc.push("this", child.Meta().SymbolInfo.Index))
+		case IdCatArg:
+			idx := child.Meta().SymbolInfo.Index
+			if c.curFuncInfo.kind == Method {
+				idx++
 			}
-			res = append(res, codes...)
-			codes, err = c.compile(op)
+			res = append(res, c.vmc.push("argument", idx))
+		case IdCatVar:
+			res = append(res, c.vmc.push("local", child.Meta().SymbolInfo.Index))
+		}
+		if len(term.ChildNodes()) == 4 {
+			// case of array
+			idxExp := term.ChildNodes()[2]
+			idx, err := c.compile(idxExp)
 			if err != nil {
 				return nil, fmt.Errorf("[compileExpression] %w", err)
 			}
-			res = append(res, codes...)
-		case KeywordConstantType:
-			switch child.Value() {
-			case "true":
-				res = append(res, c.vmc.true()...)
-			case "false":
-				res = append(res, c.vmc.false())
-			case "null":
-				res = append(res, c.vmc.null())
-			case "this":
-				res = append(res, c.vmc.push("pointer", 0))
-			}
-		case StrConstType:
-			res = append(res, c.vmc.newStr(child.Value())...)
-		default:
-			return nil, fmt.Errorf("[compileExpression] Invalid node %v, %v", term, child.Type())
+			res = append(res, idx...)
+			res = append(res, c.vmc.add())
+			res = append(res, c.vmc.pop("pointer", 1))
+			res = append(res, c.vmc.push("that", 0))
 		}
-	}
-
-	if len(exps) >= 3 {
-		term := exps[0]
-		op := exps[1]
-		rest := exps[2:len(exps)]
-
-		codes, err := c.traverseExpression([]TreeNode{term})
+	case ClassNameType: // Class.NAME
+		v, err := c.constant(term)
 		if err != nil {
 			return nil, fmt.Errorf("[compileExpression] %w", err)
 		}
-		res = append(res, codes...)
-
-		codes, err = c.traverseExpression(rest)
+		return c.constOperand(v), nil
+	case SubroutineCallType:
+		codes, err := c.compile(child)
 		if err != nil {
 			return nil, fmt.Errorf("[compileExpression] %w", err)
 		}
 		res = append(res, codes...)
-
-		codes, err = c.compile(op)
+	case SymbolType: // (expression)
+		o, err := c.compileOperand(term.ChildNodes()[1].ChildNodes())
 		if err != nil {
 			return nil, fmt.Errorf("[compileExpression] %w", err)
 		}
-		res = append(res, codes...)
+		return o, nil
+	case UnaryOpType:
+		op := term.ChildNodes()[0]
+		term := term.ChildNodes()[1]
+		if op.Value() == "-" && term.ChildNodes()[0].Type() == IntConstType && term.ChildNodes()[0].Value() == minIntOperand {
+			return c.constOperand(minInt), nil
+		}
+		o, err := c.compileTerm(term)
+		if err != nil {
+			return nil, fmt.Errorf("[compileExpression] %w", err)
+		}
+		o, err = c.unaryOperand(op, o)
+		if err != nil {
+			return nil, fmt.Errorf("[compileExpression] %w", err)
+		}
+		return o, nil
+	case KeywordConstantType:
+		switch child.Value() {
+		case "true":
+			return c.constOperand(-1), nil
+		case "false", "null":
+			return c.constOperand(0), nil
+		case "this":
+			res = append(res, c.vmc.push("pointer", 0))
+		}
+	case StrConstType:
+		res = append(res, c.vmc.newStr(child.Value())...)
+		c.addCall("String", "new", true)
+		if child.Value() != "" {
+			c.addCall("String", "appendChar", true)
+		}
+	default:
+		return nil, fmt.Errorf("[compileExpression] Invalid node %v, %v", term, child.Type())
+	}
+	return &operand{code: res}, nil
+}
 
-		return res, nil
+// constant returns the value of the constant which the term `Class.NAME` refers to.
+func (c *Compiler) constant(term TreeNode) (int, error) {
+	children := term.ChildNodes()
+	if s := children[2].Meta().SymbolInfo; s != nil {
+		return s.Value, nil
 	}
+	name := fmt.Sprintf("%s.%s", children[0].Value(), children[2].Value())
+	if v, ok := c.constants[name]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("undefined constant %s", name)
+}
 
-	return res, nil
+// opPrecedence returns how tightly op binds. All ops bind equally unless the precedence mode is on.
+func (c *Compiler) opPrecedence(op TreeNode) int {
+	if !c.precedence {
+		return 0
+	}
+	return OpPrecedence(op.Value())
 }
 
 func (c *Compiler) compileSubroutineCall(pt TreeNode) ([]string, error) {
@@ -671,6 +921,7 @@ func (c *Compiler) compileSubroutineCall(pt TreeNode) ([]string, error) {
 		}
 	}
 	res = append(res, c.vmc.call(className, subName, callingArgCount))
+	c.addCall(className, subName, false)
 	return res, nil
 }
 
@@ -696,8 +947,10 @@ func (c *Compiler) compileOp(pt TreeNode) ([]string, error) {
 	case "-":
 		return []string{c.vmc.sub()}, nil
 	case "*":
+		c.addCall("Math", "multiply", true)
 		return []string{c.vmc.mul()}, nil
 	case "/":
+		c.addCall("Math", "divide", true)
 		return []string{c.vmc.div()}, nil
 	case "&":
 		return []string{c.vmc.and()}, nil
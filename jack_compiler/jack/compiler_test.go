@@ -1,6 +1,7 @@
-package main
+package jack
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -137,11 +138,32 @@ func TestCompiler_compileExpression(t *testing.T) {
 	type args struct {
 		pt TreeNode
 	}
+	op := func(v string) TreeNode {
+		return MockNodes([]TreeNode{AdaptTokenToNode(SymbolToken(v))}, OpType, true)
+	}
+	num := func(i int) TreeNode {
+		return MockNodes([]TreeNode{AdaptTokenToNode(IntConstToken(i))}, TermType, false)
+	}
+	x := MockNodes([]TreeNode{
+		MockNodes([]TreeNode{AdaptTokenToNodeWithMeta(IdentifierToken("x"), &IDMeta{Category: IdCatVar, SymbolInfo: &SymbolInfo{Index: 2}})}, VarNameType, true),
+	}, TermType, false)
+	unary := func(v string, term TreeNode) TreeNode {
+		return MockNodes([]TreeNode{MockNodes([]TreeNode{AdaptTokenToNode(SymbolToken(v))}, UnaryOpType, true), term}, TermType, false)
+	}
+	paren := func(exps ...TreeNode) TreeNode {
+		return MockNodes([]TreeNode{
+			MockNodes([]TreeNode{AdaptTokenToNode(SymbolToken("("))}, SymbolType, true),
+			MockNodes(exps, ExpressionType, true),
+			MockNodes([]TreeNode{AdaptTokenToNode(SymbolToken(")"))}, SymbolType, true),
+		}, TermType, false)
+	}
 	tests := []struct {
-		name    string
-		args    args
-		want    []string
-		wantErr bool
+		name       string
+		args       args
+		precedence bool
+		optimize   bool
+		want       []string
+		wantErr    bool
 	}{
 		{
 			name: "int calculation",
@@ -157,9 +179,46 @@ func TestCompiler_compileExpression(t *testing.T) {
 			want: []string{
 				"push constant 10",
 				"push constant 11",
+				"add",
 				"push constant 12",
 				"add",
+			},
+			wantErr: false,
+		},
+		{
+			name: "left to right",
+			args: args{
+				MockNodes([]TreeNode{num(10), op("-"), num(11), op("*"), num(12)}, ExpressionType, true),
+			},
+			want: []string{
+				"push constant 10",
+				"push constant 11",
+				"sub",
+				"push constant 12",
+				"call Math.multiply 2",
+			},
+			wantErr: false,
+		},
+		{
+			name: "precedence",
+			args: args{
+				MockNodes([]TreeNode{num(1), op("+"), num(2), op("*"), num(3), op("-"), num(4), op("<"), num(5), op("&"), num(6), op("/"), num(7)}, ExpressionType, true),
+			},
+			precedence: true,
+			want: []string{
+				"push constant 1",
+				"push constant 2",
+				"push constant 3",
+				"call Math.multiply 2",
 				"add",
+				"push constant 4",
+				"sub",
+				"push constant 5",
+				"lt",
+				"push constant 6",
+				"push constant 7",
+				"call Math.divide 2",
+				"and",
 			},
 			wantErr: false,
 		},
@@ -251,11 +310,150 @@ func TestCompiler_compileExpression(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "empty string",
+			args: args{
+				MockNodes([]TreeNode{
+					MockNodes([]TreeNode{AdaptTokenToNode(StrConstToken(""))}, TermType, false),
+				}, ExpressionType, true),
+			},
+			want: []string{
+				"push constant 0",
+				"call String.new 1",
+			},
+			wantErr: false,
+		},
+		{
+			name: "minimum int",
+			args: args{
+				MockNodes([]TreeNode{
+					MockNodes([]TreeNode{
+						MockNodes([]TreeNode{AdaptTokenToNode(SymbolToken("-"))}, UnaryOpType, true),
+						MockNodes([]TreeNode{AdaptTokenToNode(IntConstToken(32768))}, TermType, false),
+					}, TermType, false),
+				}, ExpressionType, true),
+			},
+			want: []string{
+				"push constant 32767",
+				"neg",
+				"push constant 1",
+				"sub",
+			},
+			wantErr: false,
+		},
+		{
+			name: "string with escape sequences",
+			args: args{
+				MockNodes([]TreeNode{
+					MockNodes([]TreeNode{AdaptTokenToNode(StrConstToken(`\"\n`))}, TermType, false),
+				}, ExpressionType, true),
+			},
+			want: []string{
+				"push constant 2",
+				"call String.new 1",
+				"push constant 34",
+				"call String.appendChar 2",
+				"push constant 128",
+				"call String.appendChar 2",
+			},
+			wantErr: false,
+		},
+		{
+			name: "fold constants",
+			args: args{
+				MockNodes([]TreeNode{num(2), op("*"), num(3), op("-"), num(10)}, ExpressionType, true),
+			},
+			optimize: true,
+			want: []string{
+				"push constant 4",
+				"neg",
+			},
+		},
+		{
+			name: "fold with wraparound",
+			args: args{
+				MockNodes([]TreeNode{num(32767), op("+"), paren(num(2), op("/"), num(2))}, ExpressionType, true),
+			},
+			optimize: true,
+			want: []string{
+				"push constant 32767",
+				"neg",
+				"push constant 1",
+				"sub",
+			},
+		},
+		{
+			name: "not fold division by zero",
+			args: args{
+				MockNodes([]TreeNode{num(1), op("/"), num(0)}, ExpressionType, true),
+			},
+			optimize: true,
+			want: []string{
+				"push constant 1",
+				"push constant 0",
+				"call Math.divide 2",
+			},
+		},
+		{
+			name: "multiply by power of two",
+			args: args{
+				MockNodes([]TreeNode{num(4), op("*"), x}, ExpressionType, true),
+			},
+			optimize: true,
+			want: []string{
+				"push local 2",
+				"pop temp 0",
+				"push temp 0",
+				"push temp 0",
+				"add",
+				"pop temp 0",
+				"push temp 0",
+				"push temp 0",
+				"add",
+			},
+		},
+		{
+			name: "remove identities",
+			args: args{
+				MockNodes([]TreeNode{x, op("*"), num(1), op("-"), num(0), op("/"), num(1), op("*"), num(3)}, ExpressionType, true),
+			},
+			optimize: true,
+			want: []string{
+				"push local 2",
+				"push constant 3",
+				"call Math.multiply 2",
+			},
+		},
+		{
+			name: "fold unary ops",
+			args: args{
+				MockNodes([]TreeNode{unary("~", MockNodes([]TreeNode{MockNodes([]TreeNode{AdaptTokenToNode(KeywordToken("true"))}, KeywordConstantType, true)}, TermType, false)), op("|"), unary("-", paren(unary("-", x)))}, ExpressionType, true),
+			},
+			optimize: true,
+			want: []string{
+				"push constant 0",
+				"push local 2",
+				"or",
+			},
+		},
+		{
+			name: "unary ops without optimization",
+			args: args{
+				MockNodes([]TreeNode{unary("-", paren(unary("-", x)))}, ExpressionType, true),
+			},
+			want: []string{
+				"push local 2",
+				"neg",
+				"neg",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &Compiler{
-				vmc: NewVmCode(),
+				vmc:        NewVmCode(),
+				precedence: tt.precedence,
+				optimize:   tt.optimize,
 			}
 			got, err := c.compileExpression(tt.args.pt)
 			if (err != nil) != tt.wantErr {
@@ -355,12 +553,27 @@ func TestCompiler_compileIfStatement(t *testing.T) {
 	type args struct {
 		pt TreeNode
 	}
+	x := MockNodes([]TreeNode{
+		MockNodes([]TreeNode{AdaptTokenToNodeWithMeta(IdentifierToken("x"), &IDMeta{Category: IdCatVar, SymbolInfo: &SymbolInfo{Index: 2}})}, VarNameType, true),
+	}, TermType, false)
+	cmpTerm := func(op string, i int) TreeNode {
+		return MockNodes([]TreeNode{
+			AdaptTokenToNode(SymbolToken("(")),
+			MockNodes([]TreeNode{
+				x,
+				MockNodes([]TreeNode{AdaptTokenToNode(SymbolToken(op))}, OpType, true),
+				MockNodes([]TreeNode{AdaptTokenToNode(IntConstToken(i))}, TermType, false),
+			}, ExpressionType, false),
+			AdaptTokenToNode(SymbolToken(")")),
+		}, TermType, false)
+	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    []string
-		wantErr bool
+		name         string
+		fields       fields
+		args         args
+		shortCircuit bool
+		want         []string
+		wantErr      bool
 	}{
 		{
 			name:   "if only",
@@ -538,6 +751,96 @@ func TestCompiler_compileIfStatement(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:   "short-circuit",
+			fields: fields{&classInfo{name: "MyClass"}, &funcInfo{name: "MyFunc"}, 5},
+			args: args{
+				MockNodes([]TreeNode{
+					AdaptTokenToNode(KeywordToken("if")),
+					AdaptTokenToNode(SymbolToken("(")),
+					MockNodes([]TreeNode{
+						cmpTerm("<", 10),
+						MockNodes([]TreeNode{AdaptTokenToNode(SymbolToken("|"))}, OpType, true),
+						MockNodes([]TreeNode{
+							AdaptTokenToNode(SymbolToken("(")),
+							MockNodes([]TreeNode{
+								cmpTerm(">", 20),
+								MockNodes([]TreeNode{AdaptTokenToNode(SymbolToken("&"))}, OpType, true),
+								cmpTerm("<", 30),
+							}, ExpressionType, false),
+							AdaptTokenToNode(SymbolToken(")")),
+						}, TermType, false),
+					}, ExpressionType, false),
+					AdaptTokenToNode(SymbolToken(")")),
+					AdaptTokenToNode(SymbolToken("{")),
+					MockNodes([]TreeNode{}, StatementsType, false),
+					AdaptTokenToNode(SymbolToken("}")),
+				}, IfStatementType, true),
+			},
+			shortCircuit: true,
+			want: []string{
+				"push local 2",
+				"push constant 10",
+				"lt",
+				"push constant 1",
+				"neg",
+				"eq",
+				"if-goto MyClass.MyFunc.5.IF.ELSE.SKIP.0",
+				"push local 2",
+				"push constant 20",
+				"gt",
+				"not",
+				"if-goto MyClass.MyFunc.5.IF.ELSE",
+				"push local 2",
+				"push constant 30",
+				"lt",
+				"not",
+				"if-goto MyClass.MyFunc.5.IF.ELSE",
+				"label MyClass.MyFunc.5.IF.ELSE.SKIP.0",
+				"goto MyClass.MyFunc.5.IF.END",
+				"label MyClass.MyFunc.5.IF.ELSE",
+				"label MyClass.MyFunc.5.IF.END",
+			},
+			wantErr: false,
+		},
+		{
+			// x = 1 must be false as in the normal condition, where only -1 is true
+			name:   "short-circuit non-boolean left operand",
+			fields: fields{&classInfo{name: "MyClass"}, &funcInfo{name: "MyFunc"}, 5},
+			args: args{
+				MockNodes([]TreeNode{
+					AdaptTokenToNode(KeywordToken("if")),
+					AdaptTokenToNode(SymbolToken("(")),
+					MockNodes([]TreeNode{
+						x,
+						MockNodes([]TreeNode{AdaptTokenToNode(SymbolToken("|"))}, OpType, true),
+						cmpTerm("<", 0),
+					}, ExpressionType, false),
+					AdaptTokenToNode(SymbolToken(")")),
+					AdaptTokenToNode(SymbolToken("{")),
+					MockNodes([]TreeNode{}, StatementsType, false),
+					AdaptTokenToNode(SymbolToken("}")),
+				}, IfStatementType, true),
+			},
+			shortCircuit: true,
+			want: []string{
+				"push local 2",
+				"push constant 1",
+				"neg",
+				"eq",
+				"if-goto MyClass.MyFunc.5.IF.ELSE.SKIP.0",
+				"push local 2",
+				"push constant 0",
+				"lt",
+				"not",
+				"if-goto MyClass.MyFunc.5.IF.ELSE",
+				"label MyClass.MyFunc.5.IF.ELSE.SKIP.0",
+				"goto MyClass.MyFunc.5.IF.END",
+				"label MyClass.MyFunc.5.IF.ELSE",
+				"label MyClass.MyFunc.5.IF.END",
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -546,6 +849,7 @@ func TestCompiler_compileIfStatement(t *testing.T) {
 				curFuncInfo:  tt.fields.curFuncInfo,
 				ifCounter:    tt.fields.ifCounter,
 				vmc:          NewVmCode(),
+				shortCircuit: tt.shortCircuit,
 			}
 			got, err := c.compileIfStatement(tt.args.pt)
 			if (err != nil) != tt.wantErr {
@@ -747,6 +1051,171 @@ func TestCompiler_compileWhileStatement(t *testing.T) {
 	}
 }
 
+func TestCompiler_compileForStatement(t *testing.T) {
+	src := "class Main {\n  function void f(int n) {\n    var int i;\n" +
+		"    for (let i = 0; i < n; let i = i + 1) {\n      if (i = 2) { continue; }\n      while (true) { break; }\n    }\n" +
+		"    return;\n  }\n}"
+	got, err := NewCompiler().Compile(parseForTest(t, src, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"function Main.f 1",
+		"push constant 0",
+		"pop local 0",
+		"label Main.f.0.FOR.COND",
+		"push local 0",
+		"push argument 0",
+		"lt",
+		"not",
+		"if-goto Main.f.0.FOR.END",
+		"push local 0",
+		"push constant 2",
+		"eq",
+		"not",
+		"if-goto Main.f.0.IF.ELSE",
+		"goto Main.f.0.FOR.CONT",
+		"goto Main.f.0.IF.END",
+		"label Main.f.0.IF.ELSE",
+		"label Main.f.0.IF.END",
+		"label Main.f.0.WHILE.CONT",
+		"push constant 1",
+		"neg",
+		"not",
+		"if-goto Main.f.0.WHILE.END",
+		"goto Main.f.0.WHILE.END",
+		"goto Main.f.0.WHILE.CONT",
+		"label Main.f.0.WHILE.END",
+		"label Main.f.0.FOR.CONT",
+		"push local 0",
+		"push constant 1",
+		"add",
+		"pop local 0",
+		"goto Main.f.0.FOR.COND",
+		"label Main.f.0.FOR.END",
+		"push constant 0",
+		"return",
+	}
+	if diff := cmp.Diff(strings.Split(got, "\n"), want); diff != "" {
+		t.Errorf("Compiler.Compile() diff (-got +want)\n%s", diff)
+	}
+}
+
+func TestCompiler_compileSwitchStatement(t *testing.T) {
+	src := "class Main {\n  function int f(int n) {\n" +
+		"    if (n = 1) { return 1; } else if (n = 2) { return 2; }\n" +
+		"    switch (n) {\n      case 3: { return 3; }\n      case 'A': { return 4; }\n      default: { return 5; }\n    }\n" +
+		"  }\n}"
+	got, err := NewCompiler().Compile(parseForTest(t, src, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"function Main.f 0",
+		"push argument 0",
+		"push constant 1",
+		"eq",
+		"not",
+		"if-goto Main.f.0.IF.ELSE",
+		"push constant 1",
+		"return",
+		"goto Main.f.0.IF.END",
+		"label Main.f.0.IF.ELSE",
+		"push argument 0",
+		"push constant 2",
+		"eq",
+		"not",
+		"if-goto Main.f.1.IF.ELSE",
+		"push constant 2",
+		"return",
+		"goto Main.f.1.IF.END",
+		"label Main.f.1.IF.ELSE",
+		"label Main.f.1.IF.END",
+		"label Main.f.0.IF.END",
+		"push argument 0",
+		"pop temp 0",
+		"push temp 0",
+		"push constant 3",
+		"eq",
+		"if-goto Main.f.0.SWITCH.CASE.0",
+		"push temp 0",
+		"push constant 65",
+		"eq",
+		"if-goto Main.f.0.SWITCH.CASE.1",
+		"goto Main.f.0.SWITCH.DEFAULT",
+		"label Main.f.0.SWITCH.CASE.0",
+		"push constant 3",
+		"return",
+		"goto Main.f.0.SWITCH.END",
+		"label Main.f.0.SWITCH.CASE.1",
+		"push constant 4",
+		"return",
+		"goto Main.f.0.SWITCH.END",
+		"label Main.f.0.SWITCH.DEFAULT",
+		"push constant 5",
+		"return",
+		"goto Main.f.0.SWITCH.END",
+		"label Main.f.0.SWITCH.END",
+	}
+	if diff := cmp.Diff(strings.Split(got, "\n"), want); diff != "" {
+		t.Errorf("Compiler.Compile() diff (-got +want)\n%s", diff)
+	}
+}
+
+func TestCompiler_compileConstants(t *testing.T) {
+	src := "class Main {\n  const int WIDTH = 512;\n  const int NEG = -3;\n  enum Dir { UP, DOWN }\n  static int s;\n" +
+		"  function void f(int d) {\n    let s = Main.WIDTH + Main.NEG + Dir.DOWN + Other.HEIGHT;\n" +
+		"    switch (d) { case Dir.UP: { return; } case Other.HEIGHT: { return; } }\n    return;\n  }\n}"
+	tree := parseForTest(t, src, true)
+	compiler := NewCompiler()
+	compiler.SetConstants(map[string]int{"Other.HEIGHT": 256})
+	got, err := compiler.Compile(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"function Main.f 0",
+		"push constant 512",
+		"push constant 3",
+		"neg",
+		"add",
+		"push constant 1",
+		"add",
+		"push constant 256",
+		"add",
+		"pop static 0",
+		"push argument 0",
+		"pop temp 0",
+		"push temp 0",
+		"push constant 0",
+		"eq",
+		"if-goto Main.f.0.SWITCH.CASE.0",
+		"push temp 0",
+		"push constant 256",
+		"eq",
+		"if-goto Main.f.0.SWITCH.CASE.1",
+		"goto Main.f.0.SWITCH.END",
+		"label Main.f.0.SWITCH.CASE.0",
+		"push constant 0",
+		"return",
+		"goto Main.f.0.SWITCH.END",
+		"label Main.f.0.SWITCH.CASE.1",
+		"push constant 0",
+		"return",
+		"goto Main.f.0.SWITCH.END",
+		"label Main.f.0.SWITCH.END",
+		"push constant 0",
+		"return",
+	}
+	if diff := cmp.Diff(strings.Split(got, "\n"), want); diff != "" {
+		t.Errorf("Compiler.Compile() diff (-got +want)\n%s", diff)
+	}
+
+	if _, err := NewCompiler().Compile(tree); err == nil {
+		t.Errorf("Compiler.Compile() error = nil, want undefined constant Other.HEIGHT")
+	}
+}
+
 func TestCompiler_compileReturnStatement(t *testing.T) {
 	type args struct {
 		pt TreeNode
@@ -0,0 +1,269 @@
+package jack
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+// ClassDoc is the API reference of a class made from its declarations and doc comments.
+type ClassDoc struct {
+	Name        string
+	Doc         string
+	Vars        []*VarDoc
+	Subroutines []*SubroutineDoc
+}
+
+// VarDoc is a class variable declaration, which may declare several variables.
+type VarDoc struct {
+	Kind  string
+	Type  string
+	Names []string
+	Doc   string
+}
+
+func (v *VarDoc) Declaration() string {
+	return fmt.Sprintf("%s %s %s", v.Kind, v.Type, strings.Join(v.Names, ", "))
+}
+
+// SubroutineDoc is a subroutine declaration.
+type SubroutineDoc struct {
+	Kind   string
+	Type   string
+	Name   string
+	Params []ParamDoc
+	Doc    string
+}
+
+type ParamDoc struct {
+	Type string
+	Name string
+}
+
+func (s *SubroutineDoc) Signature() string {
+	var params []string
+	for _, p := range s.Params {
+		params = append(params, p.Type+" "+p.Name)
+	}
+	return fmt.Sprintf("%s %s %s(%s)", s.Kind, s.Type, s.Name, strings.Join(params, ", "))
+}
+
+// NewClassDoc collects the declarations of the class and attaches the doc comments to them.
+// A doc comment is a /** */ comment followed by the declaration with only white spaces in between.
+func NewClassDoc(src []byte, class TreeNode, comments []Comment) *ClassDoc {
+	d := &ClassDoc{
+		Doc: docComment(src, comments, class.Pos()),
+	}
+	for _, n := range class.ChildNodes() {
+		switch n.Type() {
+		case ClassNameType:
+			d.Name = n.Value()
+		case ClassVarDecType:
+			v := &VarDoc{
+				Kind: n.ChildNodes()[0].Value(),
+				Doc:  docComment(src, comments, n.Pos()),
+			}
+			for _, c := range n.ChildNodes() {
+				switch c.Type() {
+				case TypeType:
+					v.Type = c.Value()
+				case VarNameType:
+					v.Names = append(v.Names, c.Value())
+				}
+			}
+			d.Vars = append(d.Vars, v)
+		case SubroutineDecType:
+			children := n.ChildNodes()
+			s := &SubroutineDoc{
+				Kind: children[0].Value(),
+				Type: children[1].Value(),
+				Doc:  docComment(src, comments, n.Pos()),
+			}
+			for _, c := range children {
+				switch c.Type() {
+				case SubroutineNameType:
+					s.Name = c.Value()
+				case ParameterListType:
+					for _, p := range c.ChildNodes() {
+						switch p.Type() {
+						case TypeType:
+							s.Params = append(s.Params, ParamDoc{Type: p.Value()})
+						case VarNameType:
+							s.Params[len(s.Params)-1].Name = p.Value()
+						}
+					}
+				}
+			}
+			d.Subroutines = append(d.Subroutines, s)
+		}
+	}
+	return d
+}
+
+// docComment returns the text of the doc comment just before the position, without delimiters and leading asterisks.
+func docComment(src []byte, comments []Comment, pos Pos) string {
+	for i := len(comments) - 1; i >= 0; i-- {
+		c := comments[i]
+		if c.E.Offset > pos.Offset {
+			continue
+		}
+		if !strings.HasPrefix(c.Text, "/**") || len(bytes.TrimSpace(src[c.E.Offset:pos.Offset])) > 0 {
+			return ""
+		}
+		text := strings.TrimSuffix(strings.TrimPrefix(c.Text, "/**"), "*/")
+		var lines []string
+		for _, l := range strings.Split(text, "\n") {
+			l = strings.TrimSpace(l)
+			l = strings.TrimPrefix(l, "*")
+			lines = append(lines, strings.TrimSpace(l))
+		}
+		return strings.TrimSpace(strings.Join(lines, "\n"))
+	}
+	return ""
+}
+
+// Summary returns the first sentence of the doc of the class.
+func (d *ClassDoc) Summary() string {
+	p := strings.Join(strings.Fields(paragraphs(d.Doc)[0]), " ")
+	if i := strings.Index(p, ". "); i >= 0 {
+		return p[:i+1]
+	}
+	return p
+}
+
+// paragraphs splits the doc at blank lines. It returns one empty paragraph for an empty doc.
+func paragraphs(doc string) []string {
+	var res []string
+	for _, p := range strings.Split(doc, "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			res = append(res, p)
+		}
+	}
+	if len(res) == 0 {
+		return []string{""}
+	}
+	return res
+}
+
+// SortClassDocs sorts the docs by class name.
+func SortClassDocs(docs []*ClassDoc) {
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].Name < docs[j].Name
+	})
+}
+
+// Markdown returns the reference page of the class in Markdown.
+func (d *ClassDoc) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# class %s\n", d.Name)
+	writeMarkdownDoc(&b, d.Doc)
+	if len(d.Vars) > 0 {
+		b.WriteString("\n## Variables\n")
+		for _, v := range d.Vars {
+			fmt.Fprintf(&b, "\n### `%s`\n", v.Declaration())
+			writeMarkdownDoc(&b, v.Doc)
+		}
+	}
+	if len(d.Subroutines) > 0 {
+		b.WriteString("\n## Subroutines\n")
+		for _, s := range d.Subroutines {
+			fmt.Fprintf(&b, "\n### `%s`\n", s.Signature())
+			writeMarkdownDoc(&b, s.Doc)
+		}
+	}
+	return b.String()
+}
+
+func writeMarkdownDoc(b *strings.Builder, doc string) {
+	if doc != "" {
+		fmt.Fprintf(b, "\n%s\n", doc)
+	}
+}
+
+// DocIndexMarkdown returns the index of the classes in Markdown, linking to the page of each class named Class.md.
+func DocIndexMarkdown(docs []*ClassDoc) string {
+	var b strings.Builder
+	b.WriteString("# Classes\n\n")
+	for _, d := range docs {
+		fmt.Fprintf(&b, "- [%s](%s.md)", d.Name, d.Name)
+		if s := d.Summary(); s != "" {
+			fmt.Fprintf(&b, ": %s", s)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// docTemplates are the templates of the HTML pages, "class" for ClassDoc and "index" for the list of them.
+var docTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"paragraphs": paragraphs,
+}).Parse(`{{define "class" -}}
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>class {{.Name}}</title>
+</head>
+<body>
+<p><a href="index.html">Classes</a></p>
+<h1>class {{.Name}}</h1>
+{{- template "doc" .Doc}}
+{{- if .Vars}}
+<h2>Variables</h2>
+{{- range .Vars}}
+<h3><code>{{.Declaration}}</code></h3>
+{{- template "doc" .Doc}}
+{{- end}}
+{{- end}}
+{{- if .Subroutines}}
+<h2>Subroutines</h2>
+{{- range .Subroutines}}
+<h3 id="{{.Name}}"><code>{{.Signature}}</code></h3>
+{{- template "doc" .Doc}}
+{{- end}}
+{{- end}}
+</body>
+</html>
+{{end}}
+
+{{- define "doc"}}{{if .}}{{range paragraphs .}}
+<p>{{.}}</p>{{end}}{{end}}{{end}}
+
+{{- define "index" -}}
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Classes</title>
+</head>
+<body>
+<h1>Classes</h1>
+<dl>
+{{- range .}}
+<dt><a href="{{.Name}}.html">{{.Name}}</a></dt>
+<dd>{{.Summary}}</dd>
+{{- end}}
+</dl>
+</body>
+</html>
+{{end}}`))
+
+// HTML returns the reference page of the class in HTML.
+func (d *ClassDoc) HTML() (string, error) {
+	var b strings.Builder
+	if err := docTemplates.ExecuteTemplate(&b, "class", d); err != nil {
+		return "", fmt.Errorf("[ClassDoc.HTML] %w", err)
+	}
+	return b.String(), nil
+}
+
+// DocIndexHTML returns the index of the classes in HTML, linking to the page of each class named Class.html.
+func DocIndexHTML(docs []*ClassDoc) (string, error) {
+	var b strings.Builder
+	if err := docTemplates.ExecuteTemplate(&b, "index", docs); err != nil {
+		return "", fmt.Errorf("[DocIndexHTML] %w", err)
+	}
+	return b.String(), nil
+}
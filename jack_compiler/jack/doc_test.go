@@ -0,0 +1,135 @@
+package jack
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const docTestSrc = `// File name: Point.jack
+
+/**
+ * A point on the screen. Points are immutable.
+ *
+ * See also <Screen>.
+ */
+class Point {
+    /** coordinates */
+    field int x, y;
+    static int count; // not a doc comment
+
+    /** Makes a point. */
+    constructor Point new(int ax, int ay) {
+        return this;
+    }
+
+    /* not a doc comment */
+    method int getX() {
+        return x;
+    }
+
+    /** Attached to nothing. */
+
+    // separated by a line comment
+    function void reset() {
+        return;
+    }
+}
+`
+
+func newClassDocForTest(t *testing.T, src string) *ClassDoc {
+	t.Helper()
+	tokenizer := NewTokenizer(strings.NewReader(src), "Point.jack")
+	tokens, err := tokenizer.Tokenize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := NewParser().Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClassDoc([]byte(src), tree, tokenizer.Comments())
+}
+
+func TestNewClassDoc(t *testing.T) {
+	got := newClassDocForTest(t, docTestSrc)
+	want := &ClassDoc{
+		Name: "Point",
+		Doc:  "A point on the screen. Points are immutable.\n\nSee also <Screen>.",
+		Vars: []*VarDoc{
+			{Kind: "field", Type: "int", Names: []string{"x", "y"}, Doc: "coordinates"},
+			{Kind: "static", Type: "int", Names: []string{"count"}},
+		},
+		Subroutines: []*SubroutineDoc{
+			{Kind: "constructor", Type: "Point", Name: "new", Params: []ParamDoc{{"int", "ax"}, {"int", "ay"}}, Doc: "Makes a point."},
+			{Kind: "method", Type: "int", Name: "getX"},
+			{Kind: "function", Type: "void", Name: "reset"},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("NewClassDoc() diff (-got +want)\n%s", diff)
+	}
+	if s := got.Summary(); s != "A point on the screen." {
+		t.Errorf("Summary() = %q", s)
+	}
+}
+
+func TestClassDoc_Markdown(t *testing.T) {
+	d := newClassDocForTest(t, docTestSrc)
+	want := "# class Point\n\nA point on the screen. Points are immutable.\n\nSee also <Screen>.\n\n" +
+		"## Variables\n\n### `field int x, y`\n\ncoordinates\n\n### `static int count`\n\n" +
+		"## Subroutines\n\n### `constructor Point new(int ax, int ay)`\n\nMakes a point.\n\n" +
+		"### `method int getX()`\n\n### `function void reset()`\n"
+	if diff := cmp.Diff(d.Markdown(), want); diff != "" {
+		t.Errorf("Markdown() diff (-got +want)\n%s", diff)
+	}
+
+	wantIndex := "# Classes\n\n- [Point](Point.md): A point on the screen.\n"
+	if diff := cmp.Diff(DocIndexMarkdown([]*ClassDoc{d}), wantIndex); diff != "" {
+		t.Errorf("DocIndexMarkdown() diff (-got +want)\n%s", diff)
+	}
+}
+
+func TestClassDoc_HTML(t *testing.T) {
+	d := newClassDocForTest(t, docTestSrc)
+	got, err := d.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>class Point</title>
+</head>
+<body>
+<p><a href="index.html">Classes</a></p>
+<h1>class Point</h1>
+<p>A point on the screen. Points are immutable.</p>
+<p>See also &lt;Screen&gt;.</p>
+<h2>Variables</h2>
+<h3><code>field int x, y</code></h3>
+<p>coordinates</p>
+<h3><code>static int count</code></h3>
+<h2>Subroutines</h2>
+<h3 id="new"><code>constructor Point new(int ax, int ay)</code></h3>
+<p>Makes a point.</p>
+<h3 id="getX"><code>method int getX()</code></h3>
+<h3 id="reset"><code>function void reset()</code></h3>
+</body>
+</html>
+`
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("HTML() diff (-got +want)\n%s", diff)
+	}
+
+	index, err := DocIndexHTML([]*ClassDoc{d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(index, `<dt><a href="Point.html">Point</a></dt>
+<dd>A point on the screen.</dd>`) {
+		t.Errorf("DocIndexHTML() = %s", index)
+	}
+}
@@ -0,0 +1,51 @@
+package jack
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TreeDot returns the parse tree in Graphviz dot language. Inner nodes are ellipses labeled with their names,
+// nodes with one child are dashed, and leaf nodes are boxes labeled with their names and values.
+func TreeDot(tree TreeNode) string {
+	res := []string{
+		"digraph tree {",
+		"  ordering=out;",
+	}
+	id := 0
+	var visit func(n TreeNode) string
+	visit = func(n TreeNode) string {
+		name := fmt.Sprintf("n%d", id)
+		id++
+		switch n.(type) {
+		case *LeafNode:
+			label := n.Name() + "\n" + n.Value()
+			res = append(res, fmt.Sprintf("  %s [shape=box, label=%s];", name, dotQuote(label)))
+		case *OneChildNode:
+			res = append(res, fmt.Sprintf("  %s [style=dashed, label=%s];", name, dotQuote(n.Name())))
+		default:
+			res = append(res, fmt.Sprintf("  %s [label=%s];", name, dotQuote(n.Name())))
+		}
+		for _, c := range n.ChildNodes() {
+			res = append(res, fmt.Sprintf("  %s -> %s;", name, visit(c)))
+		}
+		return name
+	}
+	visit(tree)
+	res = append(res, "}")
+	return strings.Join(res, "\n")
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func dotAttrs(attrs []string) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [%s]", strings.Join(attrs, ", "))
+}
@@ -0,0 +1,45 @@
+package jack
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTreeDot(t *testing.T) {
+	tree := MockNodes([]TreeNode{
+		AdaptTokenToNode(KeywordToken("return")),
+		MockNodes([]TreeNode{
+			MockNodes([]TreeNode{AdaptTokenToNode(StrConstToken(`say "hi"`))}, TermType, true),
+			MockNodes([]TreeNode{AdaptTokenToNode(SymbolToken("+"))}, OpType, true),
+			MockNodes([]TreeNode{AdaptTokenToNode(IntConstToken(1))}, TermType, true),
+		}, ExpressionType, true),
+		AdaptTokenToNode(SymbolToken(";")),
+	}, ReturnStatementType, true)
+
+	want := `digraph tree {
+  ordering=out;
+  n0 [label="returnStatement"];
+  n1 [shape=box, label="keyword\nreturn"];
+  n0 -> n1;
+  n2 [label="expression"];
+  n3 [label="term"];
+  n4 [shape=box, label="stringConstant\nsay \"hi\""];
+  n3 -> n4;
+  n2 -> n3;
+  n5 [style=dashed, label="op"];
+  n6 [shape=box, label="symbol\n+"];
+  n5 -> n6;
+  n2 -> n5;
+  n7 [label="term"];
+  n8 [shape=box, label="integerConstant\n1"];
+  n7 -> n8;
+  n2 -> n7;
+  n0 -> n2;
+  n9 [shape=box, label="symbol\n;"];
+  n0 -> n9;
+}`
+	if diff := cmp.Diff(TreeDot(tree), want); diff != "" {
+		t.Errorf("TreeDot() diff (-got +want)\n%s", diff)
+	}
+}
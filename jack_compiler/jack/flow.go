@@ -0,0 +1,330 @@
+package jack
+
+import "fmt"
+
+// FlowCheck analyzes the control flow of the subroutines in the class.
+// Subroutines which can reach the end without return, return statements not matching the declaration and
+// constructors not returning this are errors, or warnings unless asError is true. Unreachable statements,
+// locals used before assignment, variables never read and discarded return values are always warnings.
+func (p *Program) FlowCheck(class TreeNode, asError bool) error {
+	c := &flowChecker{
+		prog:    p,
+		used:    make(map[string]bool),
+		warning: !asError,
+	}
+	c.checkClass(class)
+	return c.errs.Err()
+}
+
+type flowChecker struct {
+	prog      *Program
+	className string
+	sub       *SubroutineSig
+	used      map[string]bool // class variables which are read
+	localUsed map[string]bool // parameters and locals of the current subroutine which are read
+	warned    map[string]bool // locals already reported as used before assignment
+	warning   bool            // errorf reports warnings
+	errs      SemanticErrorList
+}
+
+func (c *flowChecker) checkClass(class TreeNode) {
+	var vars []TreeNode
+	for _, n := range class.ChildNodes() {
+		switch n.Type() {
+		case ClassNameType:
+			c.className = n.Value()
+		case ClassVarDecType:
+			vars = append(vars, declaredVars(n)...)
+		case SubroutineDecType:
+			c.checkSubroutine(n)
+		}
+	}
+	c.reportUnused(vars, c.used)
+}
+
+func (c *flowChecker) checkSubroutine(dec TreeNode) {
+	c.sub = newSubroutineSig(c.className, dec)
+	c.localUsed = make(map[string]bool)
+	c.warned = make(map[string]bool)
+
+	var vars []TreeNode
+	var body TreeNode
+	for _, n := range dec.ChildNodes() {
+		switch n.Type() {
+		case ParameterListType:
+			vars = append(vars, declaredVars(n)...)
+		case SubroutineBodyType:
+			body = n
+		}
+	}
+	for _, n := range body.ChildNodes() {
+		switch n.Type() {
+		case VarDecType:
+			vars = append(vars, declaredVars(n)...)
+		case StatementsType:
+			if !c.statements(n, make(map[string]bool)) {
+				closeBracket := body.ChildNodes()[len(body.ChildNodes())-1]
+				c.errorf(closeBracket.Pos(), "missing return at end of %s", c.sub.FullName())
+			}
+		}
+	}
+	c.reportUnused(vars, c.localUsed)
+}
+
+// statements checks the statements with the set of locals assigned before them, which is updated in place.
+// It reports whether control never reaches their end, since they return, or break or continue a loop.
+func (c *flowChecker) statements(node TreeNode, assigned map[string]bool) bool {
+	stmts := node.ChildNodes()
+	for i, st := range stmts {
+		if !c.statement(st.ChildNodes()[0], assigned) {
+			continue
+		}
+		if i+1 < len(stmts) {
+			c.warnf(stmts[i+1].Pos(), "unreachable statement")
+		}
+		return true
+	}
+	return false
+}
+
+func (c *flowChecker) statement(node TreeNode, assigned map[string]bool) bool {
+	children := node.ChildNodes()
+	switch node.Type() {
+	case LetStatementType:
+		target := children[1]
+		if isArrayLet(node) {
+			c.reads(children[3], assigned)
+			c.reads(children[6], assigned)
+			c.read(target, assigned)
+			return false
+		}
+		c.reads(children[3], assigned)
+		if target.Meta() != nil && target.Meta().SymbolInfo != nil {
+			assigned[varKey(target)] = true
+		}
+	case IfStatementType:
+		c.reads(children[2], assigned)
+		thenAssigned := copySet(assigned)
+		thenReturns := c.statements(children[5], thenAssigned)
+		elseAssigned := copySet(assigned)
+		elseReturns := false
+		switch e := elseBranch(node); {
+		case e == nil:
+		case e.Type() == IfStatementType:
+			elseReturns = c.statement(e, elseAssigned)
+		default:
+			elseReturns = c.statements(e, elseAssigned)
+		}
+		switch {
+		case thenReturns && elseReturns:
+			return true
+		case thenReturns:
+			replaceSet(assigned, elseAssigned)
+		case elseReturns:
+			replaceSet(assigned, thenAssigned)
+		default:
+			for k := range thenAssigned {
+				if !elseAssigned[k] {
+					delete(thenAssigned, k)
+				}
+			}
+			replaceSet(assigned, thenAssigned)
+		}
+	case WhileStatementType:
+		c.reads(children[2], assigned)
+		c.statements(children[5], copySet(assigned))
+		// `while (true)` ends only by break
+		return isKeywordConstant(children[2], "true") && !hasBreak(children[5])
+	case ForStatementType:
+		c.statement(children[2], assigned)
+		c.reads(children[3], assigned)
+		body := copySet(assigned)
+		c.statements(children[8], body)
+		c.statement(children[5], body)
+		return isKeywordConstant(children[3], "true") && !hasBreak(children[8])
+	case SwitchStatementType:
+		return c.switchStatement(node, assigned)
+	case BreakStatementType, ContinueStatementType:
+		return true
+	case DoStatementType:
+		call := children[1]
+		c.reads(call, assigned)
+		if sig := c.prog.callee(c.className, call); sig != nil && sig.Type != "void" {
+			c.warnf(call.Pos(), "return value of %s is discarded", sig.FullName())
+		}
+	case ReturnStatementType:
+		c.checkReturn(node, assigned)
+		return true
+	}
+	return false
+}
+
+// switchStatement checks the cases like if statements. It never falls through if the default case and all cases do not.
+func (c *flowChecker) switchStatement(node TreeNode, assigned map[string]bool) bool {
+	children := node.ChildNodes()
+	c.reads(children[2], assigned)
+
+	var outs []map[string]bool // locals assigned in the ways falling through
+	hasDefault := false
+	for _, sc := range children[5 : len(children)-1] {
+		caseChildren := sc.ChildNodes()
+		hasDefault = hasDefault || caseChildren[0].Value() == "default"
+		caseAssigned := copySet(assigned)
+		if !c.statements(caseChildren[len(caseChildren)-2], caseAssigned) {
+			outs = append(outs, caseAssigned)
+		}
+	}
+	if !hasDefault {
+		outs = append(outs, copySet(assigned))
+	}
+	if len(outs) == 0 {
+		return true
+	}
+	for k := range outs[0] {
+		for _, out := range outs[1:] {
+			if !out[k] {
+				delete(outs[0], k)
+				break
+			}
+		}
+	}
+	replaceSet(assigned, outs[0])
+	return false
+}
+
+func (c *flowChecker) checkReturn(node TreeNode, assigned map[string]bool) {
+	children := node.ChildNodes()
+	hasValue := len(children) == 3
+	if hasValue {
+		c.reads(children[1], assigned)
+	}
+
+	switch {
+	case c.sub.Kind == Constructor:
+		if !hasValue || !isKeywordConstant(children[1], "this") {
+			c.errorf(node.Pos(), "constructor %s must return this", c.sub.FullName())
+		}
+	case c.sub.Type == "void" && hasValue:
+		c.errorf(children[1].Pos(), "void %s returns a value", c.sub.FullName())
+	case c.sub.Type != "void" && !hasValue:
+		c.errorf(node.Pos(), "%s must return a value of type %s", c.sub.FullName(), c.sub.Type)
+	}
+}
+
+// reads marks the variables in the node as read.
+func (c *flowChecker) reads(node TreeNode, assigned map[string]bool) {
+	if node.Type() == VarNameType {
+		c.read(node, assigned)
+		return
+	}
+	for _, n := range node.ChildNodes() {
+		c.reads(n, assigned)
+	}
+}
+
+func (c *flowChecker) read(varName TreeNode, assigned map[string]bool) {
+	meta := varName.Meta()
+	if meta == nil || meta.SymbolInfo == nil {
+		return
+	}
+	key := varKey(varName)
+	switch meta.Category {
+	case IdCatArg, IdCatVar:
+		c.localUsed[key] = true
+	default:
+		c.used[key] = true
+	}
+	if meta.Category == IdCatVar && !assigned[key] && !c.warned[key] {
+		c.warned[key] = true
+		c.warnf(varName.Pos(), "local %s may be used before assignment", varName.Value())
+	}
+}
+
+func (c *flowChecker) reportUnused(vars []TreeNode, used map[string]bool) {
+	for _, v := range vars {
+		if v.Meta() == nil || v.Meta().SymbolInfo == nil || used[varKey(v)] {
+			continue
+		}
+		var kind string
+		switch v.Meta().SymbolInfo.Kind {
+		case Static:
+			kind = "static variable"
+		case Field:
+			kind = "field"
+		case Argument:
+			kind = "parameter"
+		case Var:
+			kind = "local"
+		}
+		c.warnf(v.Pos(), "%s %s is never used", kind, v.Value())
+	}
+}
+
+func (c *flowChecker) errorf(pos Pos, format string, a ...interface{}) {
+	e := semanticError(pos, format, a...)
+	e.Warning = c.warning
+	c.errs = append(c.errs, e)
+}
+
+func (c *flowChecker) warnf(pos Pos, format string, a ...interface{}) {
+	e := semanticError(pos, format, a...)
+	e.Warning = true
+	c.errs = append(c.errs, e)
+}
+
+// declaredVars returns the variable names declared by a class var declaration, var declaration or parameter list.
+func declaredVars(dec TreeNode) []TreeNode {
+	var res []TreeNode
+	for _, n := range dec.ChildNodes() {
+		if n.Type() == VarNameType {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
+func varKey(varName TreeNode) string {
+	return fmt.Sprintf("%v %s", varName.Meta().SymbolInfo.Kind, varName.Value())
+}
+
+// hasBreak reports whether the node has a break statement of the loop enclosing it, not of nested loops.
+func hasBreak(node TreeNode) bool {
+	for _, n := range node.ChildNodes() {
+		switch n.Type() {
+		case BreakStatementType:
+			return true
+		case WhileStatementType, ForStatementType:
+			continue
+		}
+		if hasBreak(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// isKeywordConstant reports whether the expression is just the keyword constant.
+func isKeywordConstant(exp TreeNode, kw string) bool {
+	if len(exp.ChildNodes()) != 1 {
+		return false
+	}
+	term := exp.ChildNodes()[0].ChildNodes()
+	return len(term) == 1 && term[0].Type() == KeywordConstantType && term[0].Value() == kw
+}
+
+func copySet(s map[string]bool) map[string]bool {
+	res := make(map[string]bool, len(s))
+	for k, v := range s {
+		res[k] = v
+	}
+	return res
+}
+
+func replaceSet(dst, src map[string]bool) {
+	for k := range dst {
+		delete(dst, k)
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+}
@@ -0,0 +1,88 @@
+package jack
+
+import "testing"
+
+func TestProgram_FlowCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		warn bool
+		want string
+	}{
+		{
+			name: "valid",
+			src:  "class Main {\n  field int x;\n  constructor Main new(int a) { let x = a; return this; }\n  method int get(boolean b) {\n    var int i;\n    if (b) { let i = x; } else { return 0; }\n    while (i > 0) { let i = i - 1; }\n    return i;\n  }\n  function void halt() { while (true) { } }\n}",
+			want: "",
+		},
+		{
+			name: "missing return",
+			src:  "class Main {\n  function int f(boolean b) {\n    if (b) { return 1; }\n    while (b) { return 2; }\n  }\n}",
+			want: "Main.jack:5:3: missing return at end of Main.f",
+		},
+		{
+			name: "errors as warnings",
+			src:  "class Main {\n  field int x;\n  constructor Main new() { return x; }\n  function int f() { }\n}",
+			warn: true,
+			want: "Main.jack:3:28: warning: constructor Main.new must return this\n" +
+				"Main.jack:4:22: warning: missing return at end of Main.f",
+		},
+		{
+			name: "loops",
+			src:  "class Main {\n  function int f(int n) {\n    var int i;\n    for (let i = 0; i < n; let i = i + 1) {\n      if (i = 3) { break; }\n      continue;\n      let n = 1;\n    }\n    while (true) { while (true) { break; } }\n  }\n  function int g() { while (true) { break; } }\n}",
+			want: "Main.jack:7:7: warning: unreachable statement\n" +
+				"Main.jack:11:46: missing return at end of Main.g",
+		},
+		{
+			name: "else if and switch",
+			src:  "class Main {\n  function int f(int n) {\n    var int i, j;\n    if (n = 1) { return 1; } else if (n = 2) { let i = 2; } else { return 3; }\n    switch (n) {\n      case 1: { let j = 1; }\n      case 2: { return 2; }\n      default: { let j = i; }\n    }\n    switch (n) { case 1: { return 1; } default: { return j; } }\n    return 0;\n  }\n}",
+			want: "Main.jack:11:5: warning: unreachable statement",
+		},
+		{
+			name: "return statements",
+			src:  "class Main {\n  field int x;\n  constructor Main new() { let x = 0; return x; }\n  function void f() { return 1; }\n  function int g() { return; }\n}",
+			want: "Main.jack:3:39: constructor Main.new must return this\n" +
+				"Main.jack:4:30: void Main.f returns a value\n" +
+				"Main.jack:5:22: Main.g must return a value of type int",
+		},
+		{
+			name: "unreachable statement",
+			src:  "class Main {\n  function void f(boolean b) {\n    if (b) { return; } else { return; }\n    do Main.f(b);\n    return;\n  }\n}",
+			want: "Main.jack:4:5: warning: unreachable statement",
+		},
+		{
+			name: "used before assignment",
+			src:  "class Main {\n  function int f(boolean b) {\n    var int i, j, k;\n    if (b) { let i = 1; let j = 1; } else { let j = 2; }\n    while (b) { let k = 1; }\n    return i + j + k + i;\n  }\n}",
+			want: "Main.jack:6:12: warning: local i may be used before assignment\n" +
+				"Main.jack:6:20: warning: local k may be used before assignment",
+		},
+		{
+			name: "unused variables",
+			src:  "class Main {\n  field int x, y;\n  static int z;\n  method int f(int a, int b) {\n    var int i;\n    let i = 1;\n    let y = b;\n    return y;\n  }\n}",
+			want: "Main.jack:4:20: warning: parameter a is never used\n" +
+				"Main.jack:5:13: warning: local i is never used\n" +
+				"Main.jack:2:13: warning: field x is never used\n" +
+				"Main.jack:3:14: warning: static variable z is never used",
+		},
+		{
+			name: "discarded return value",
+			src:  "class Main {\n  function void f() {\n    do Math.abs(1);\n    do Output.println();\n    return;\n  }\n}",
+			want: "Main.jack:3:8: warning: return value of Math.abs is discarded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parseForTest(t, tt.src, true)
+			prog := NewProgram()
+			if err := prog.AddClass(tree); err != nil {
+				t.Fatal(err)
+			}
+			got := ""
+			if err := prog.FlowCheck(tree, !tt.warn); err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("Program.FlowCheck() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package jack
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Format pretty prints the Jack source. Statements and declarations are put on their own lines indented by
+// four spaces, binary operators are surrounded by spaces and opening braces are placed at the end of lines.
+// Comments and single blank lines between them are kept. The result has the same tokens as the source.
+// With ext the source is read with the language extensions.
+func Format(src []byte, fileName string, ext bool) ([]byte, error) {
+	tokenizer := NewTokenizer(bytes.NewReader(src), fileName)
+	tokenizer.SetExtensions(ext)
+	tokens, err := tokenizer.Tokenize()
+	if err != nil {
+		return nil, fmt.Errorf("[Format] %w", err)
+	}
+	parser := NewParser()
+	parser.SetExtensions(ext)
+	tree, err := parser.Parse(tokens)
+	if err != nil {
+		return nil, fmt.Errorf("[Format] %w", err)
+	}
+
+	f := &formatter{
+		src:      src,
+		comments: tokenizer.Comments(),
+		start:    true,
+	}
+	f.format(tree, false)
+	f.newline()
+	f.flushComments(len(src))
+	f.endLine()
+	res := []byte(f.out.String())
+
+	if err := sameTokens(tokens, tokenizer.Comments(), res, fileName, ext); err != nil {
+		return nil, fmt.Errorf("[Format] %w", err)
+	}
+	return res, nil
+}
+
+type formatter struct {
+	src        []byte
+	comments   []Comment // comments not printed yet
+	out        strings.Builder
+	line       strings.Builder
+	indent     int
+	cont       bool     // the current line continues the statement or declaration of the previous line
+	start      bool     // nothing of the current statement or declaration is printed yet
+	opened     bool     // the last printed one is an opening brace
+	prev       TreeNode // last printed token
+	unary      bool     // prev is an unary operator
+	comment    bool     // the last printed one is a comment
+	lastLine   int      // line in the source file where the last printed token or comment ends
+	lastOffset int      // offset in the source file where the last printed token or comment ends
+}
+
+func (f *formatter) format(node TreeNode, unary bool) {
+	if _, ok := node.(*LeafNode); ok {
+		f.token(node, unary)
+		return
+	}
+	for _, n := range node.ChildNodes() {
+		switch {
+		case n.Type() == SymbolType && n.Value() == "{":
+			f.token(n, false)
+			f.indent++
+			f.newline()
+		case n.Type() == SymbolType && n.Value() == "}":
+			// comments at the end of the block are indented as the block
+			f.newline()
+			f.flushComments(n.Pos().Offset)
+			f.indent--
+			f.newline()
+			f.token(n, false)
+		case n.Type() == ClassVarDecType, n.Type() == ConstDecType, n.Type() == EnumDecType, n.Type() == SubroutineDecType,
+			n.Type() == VarDecType, n.Type() == StatementType, n.Type() == SwitchCaseType:
+			f.newline()
+			f.format(n, false)
+		default:
+			f.format(n, node.Type() == UnaryOpType)
+		}
+	}
+}
+
+// newline ends the current line and starts a new statement or declaration.
+// Comments following the last token in the same line of the source file stay at the end of the line.
+func (f *formatter) newline() {
+	for f.line.Len() > 0 && len(f.comments) > 0 &&
+		len(bytes.TrimSpace(f.src[f.lastOffset:f.comments[0].P.Offset])) == 0 && f.comments[0].P.Line == f.lastLine {
+		f.printComment(len(f.src))
+	}
+	f.endLine()
+	f.cont = false
+	f.start = true
+}
+
+func (f *formatter) endLine() {
+	if f.line.Len() == 0 {
+		return
+	}
+	f.out.WriteString(strings.TrimRight(f.line.String(), " \t"))
+	f.out.WriteString("\n")
+	f.line.Reset()
+}
+
+// beginLine writes the indent of a new line, keeping a blank line before line in the source file if any.
+func (f *formatter) beginLine(line int, closing bool) {
+	if f.out.Len() > 0 && line-f.lastLine > 1 && !closing && !f.opened {
+		f.out.WriteString("\n")
+	}
+	indent := f.indent
+	if f.cont {
+		indent++
+	}
+	f.line.WriteString(strings.Repeat("    ", indent))
+}
+
+func (f *formatter) token(node TreeNode, unary bool) {
+	f.flushComments(node.Pos().Offset)
+
+	if f.line.Len() == 0 {
+		f.beginLine(node.Pos().Line, node.Type() == SymbolType && node.Value() == "}")
+	} else if f.space(node) {
+		f.line.WriteString(" ")
+	}
+	f.line.Write(f.src[node.Pos().Offset:node.End().Offset])
+
+	f.prev = node
+	f.unary = unary
+	f.comment = false
+	f.opened = node.Type() == SymbolType && node.Value() == "{"
+	f.lastLine = node.End().Line
+	f.lastOffset = node.End().Offset
+	f.start = false
+}
+
+// space reports whether a space is needed between the previous token and the token.
+func (f *formatter) space(node TreeNode) bool {
+	if f.prev == nil {
+		return false
+	}
+	if f.comment {
+		return true
+	}
+	if node.Type() == SymbolType {
+		switch node.Value() {
+		case ",", ";", ":", ")", "]", ".":
+			return false
+		case "(", "[":
+			if f.prev.Type() == IdentifierType {
+				return false
+			}
+		}
+	}
+	if f.prev.Type() == SymbolType {
+		switch f.prev.Value() {
+		case "(", "[", ".":
+			return false
+		}
+	}
+	return !f.unary
+}
+
+// flushComments prints the comments before the offset of the source file.
+func (f *formatter) flushComments(offset int) {
+	for len(f.comments) > 0 && f.comments[0].P.Offset < offset {
+		f.printComment(offset)
+	}
+}
+
+// printComment prints the first comment not printed yet. next is the offset of the next token.
+func (f *formatter) printComment(next int) {
+	c := f.comments[0]
+	f.comments = f.comments[1:]
+
+	trailing := f.line.Len() > 0 && c.P.Line == f.lastLine
+	if f.line.Len() > 0 && !trailing {
+		f.endLine()
+		f.cont = !f.start
+	}
+	if f.line.Len() == 0 {
+		f.beginLine(c.P.Line, false)
+	} else {
+		f.line.WriteString(" ")
+	}
+	f.line.WriteString(reindentComment(c.Text, f.line.String()))
+	f.comment = true
+	f.opened = false
+	f.lastLine = c.E.Line
+	f.lastOffset = c.E.Offset
+
+	// a comment on its own line stays so, and nothing can follow a line comment
+	nextLine := c.E.Line + 1
+	if len(f.comments) > 0 && f.comments[0].P.Offset < next {
+		nextLine = f.comments[0].P.Line
+	} else if next < len(f.src) {
+		nextLine = lineAt(f.src, next)
+	}
+	if strings.HasPrefix(c.Text, "//") || (!trailing && nextLine > c.E.Line) {
+		f.endLine()
+		f.cont = !f.start
+	}
+}
+
+// reindentComment aligns the lines of a multi line comment starting with '*' to the new position of the comment.
+func reindentComment(text string, prefix string) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		return text
+	}
+	for _, l := range lines[1:] {
+		if !strings.HasPrefix(strings.TrimSpace(l), "*") {
+			return text
+		}
+	}
+	indent := strings.Repeat(" ", len(prefix)-len(strings.TrimLeft(prefix, " ")))
+	for i, l := range lines[1:] {
+		lines[i+1] = indent + " " + strings.TrimSpace(l)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lineAt returns the line number of the offset in the source.
+func lineAt(src []byte, offset int) int {
+	return bytes.Count(src[:offset], []byte("\n")) + 1
+}
+
+// sameTokens checks that the formatted source has the same tokens and comments as the original.
+func sameTokens(tokens Tokens, comments []Comment, formatted []byte, fileName string, ext bool) error {
+	tokenizer := NewTokenizer(bytes.NewReader(formatted), fileName)
+	tokenizer.SetExtensions(ext)
+	got, err := tokenizer.Tokenize()
+	if err != nil {
+		return err
+	}
+	if len(got) != len(tokens) {
+		return fmt.Errorf("formatted source has %d tokens, want %d", len(got), len(tokens))
+	}
+	for i := range got {
+		if got[i].Type() != tokens[i].Type() || got[i].String() != tokens[i].String() {
+			return fmt.Errorf("%v: formatted source has token %s, want %s", got[i].Pos(), got[i], tokens[i])
+		}
+	}
+	if len(tokenizer.Comments()) != len(comments) {
+		return fmt.Errorf("formatted source has %d comments, want %d", len(tokenizer.Comments()), len(comments))
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package jack
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		ext     bool
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "indent and spacing",
+			src: `class Main{field int x,y;
+function void main(){var int a;let a=-x+(~y)*Foo.bar(a,1);
+if(a){let a[1]=2;}else{do Output.printString("a  b");}
+while(a>0){let a=a-1;}return;}}`,
+			want: `class Main {
+    field int x, y;
+    function void main() {
+        var int a;
+        let a = -x + (~y) * Foo.bar(a, 1);
+        if (a) {
+            let a[1] = 2;
+        } else {
+            do Output.printString("a  b");
+        }
+        while (a > 0) {
+            let a = a - 1;
+        }
+        return;
+    }
+}
+`,
+		},
+		{
+			name: "comments and blank lines",
+			src: `// header
+
+/**
+  * doc
+  */
+class Main {
+
+    static int s;   // trailing
+
+
+    /** main */
+    function void main() { // open
+        // leading
+        let s = 1 /* inline */ + 2;
+        let s = 1 + // broken
+            2;
+        // end of block
+    }
+} // end
+`,
+			want: `// header
+
+/**
+ * doc
+ */
+class Main {
+    static int s; // trailing
+
+    /** main */
+    function void main() { // open
+        // leading
+        let s = 1 /* inline */ + 2;
+        let s = 1 + // broken
+            2;
+        // end of block
+    }
+} // end
+`,
+		},
+		{
+			name: "empty blocks and strings",
+			src:  "class Main { function void main() { while (true) { } do Output.printString(\"\"); return; } }",
+			want: `class Main {
+    function void main() {
+        while (true) {
+        }
+        do Output.printString("");
+        return;
+    }
+}
+`,
+		},
+		{
+			name: "language extensions",
+			src: `class Main{const int MAX=10;enum Color{RED,GREEN}
+function void main(){var int i;for(let i=0;i<Main.MAX;let i=i+1){if(i=3){continue;}else if(i=7){break;}}
+switch(i){case Color.RED:{let i=1;}default:{let i=2;}}return;}}`,
+			ext: true,
+			want: `class Main {
+    const int MAX = 10;
+    enum Color {
+        RED, GREEN
+    }
+    function void main() {
+        var int i;
+        for (let i = 0; i < Main.MAX; let i = i + 1) {
+            if (i = 3) {
+                continue;
+            } else if (i = 7) {
+                break;
+            }
+        }
+        switch (i) {
+            case Color.RED: {
+                let i = 1;
+            }
+            default: {
+                let i = 2;
+            }
+        }
+        return;
+    }
+}
+`,
+		},
+		{
+			name:    "language extensions without ext",
+			src:     "class Main { function void main() { for (let i = 0; i < 10; let i = i + 1) { } return; } }",
+			wantErr: true,
+		},
+		{
+			name:    "syntax error",
+			src:     "class Main { function void main() { let = 1; } }",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format([]byte(tt.src), "Main.jack", tt.ext)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Format() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(string(got), tt.want); diff != "" {
+				t.Errorf("Format() diff (-got +want)\n%s", diff)
+			}
+			again, err := Format(got, "Main.jack", tt.ext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(string(again), string(got)); diff != "" {
+				t.Errorf("Format() is not idempotent (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFormat_projects(t *testing.T) {
+	// Jack programs and the OS of projects 09 to 12
+	var files []string
+	for _, pattern := range []string{"../../projects/*/*.jack", "../../projects/*/*/*.jack"} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, matches...)
+	}
+	for _, f := range files {
+		src, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Format checks that the tokens are not changed
+		got, err := Format(src, f, false)
+		if err != nil {
+			t.Errorf("Format(%s) error = %v", f, err)
+			continue
+		}
+		again, err := Format(got, f, false)
+		if err != nil {
+			t.Errorf("Format(%s) of formatted source error = %v", f, err)
+			continue
+		}
+		if string(again) != string(got) {
+			t.Errorf("Format(%s) is not idempotent", f)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package jack
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// LintRule is a rule of Lint which can be disabled by LintConfig.
+type LintRule struct {
+	Name string
+	Doc  string
+}
+
+var LintRules = []LintRule{
+	{"class-name", "class names are PascalCase"},
+	{"var-name", "variable names are camelCase"},
+	{"shadow", "parameters and locals do not shadow fields and static variables"},
+	{"long-subroutine", "subroutines are not longer than maxSubroutineLines lines"},
+	{"nested-if", "if statements are not nested deeper than maxIfDepth"},
+	{"array-new", "local Array variables are assigned, usually by Array.new, before their elements"},
+	{"dispose", "objects created with new in a local variable are disposed, returned or passed to others"},
+	{"bool-compare", "conditions are not compared with true or false"},
+}
+
+// LintConfig enables the lint rules and sets their limits. Rules not in Rules are enabled.
+type LintConfig struct {
+	Rules              map[string]bool `json:"rules"`
+	MaxSubroutineLines int             `json:"maxSubroutineLines"`
+	MaxIfDepth         int             `json:"maxIfDepth"`
+}
+
+func DefaultLintConfig() *LintConfig {
+	return &LintConfig{
+		Rules:              make(map[string]bool),
+		MaxSubroutineLines: 60,
+		MaxIfDepth:         3,
+	}
+}
+
+// ParseLintConfig reads the config in JSON such as {"rules": {"var-name": false}, "maxIfDepth": 4}.
+// Omitted settings are the defaults.
+func ParseLintConfig(b []byte) (*LintConfig, error) {
+	c := DefaultLintConfig()
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("[ParseLintConfig] %w", err)
+	}
+	for name := range c.Rules {
+		known := false
+		for _, r := range LintRules {
+			known = known || r.Name == name
+		}
+		if !known {
+			return nil, fmt.Errorf("[ParseLintConfig] unknown rule %s", name)
+		}
+	}
+	return c, nil
+}
+
+func (c *LintConfig) enabled(rule string) bool {
+	v, ok := c.Rules[rule]
+	return !ok || v
+}
+
+// LintIssue is a problem found by Lint.
+type LintIssue struct {
+	Pos  Pos
+	Rule string
+	Msg  string
+}
+
+func (i *LintIssue) String() string {
+	return fmt.Sprintf("%v: %s (%s)", i.Pos, i.Msg, i.Rule)
+}
+
+// Lint reports the problems of style and likely bugs in the class in order of position.
+func Lint(class TreeNode, config *LintConfig) []*LintIssue {
+	l := &linter{
+		config: config,
+		st:     NewSymbolTable(),
+	}
+	l.checkClass(class)
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		return l.issues[i].Pos.Offset < l.issues[j].Pos.Offset
+	})
+	return l.issues
+}
+
+var (
+	pascalCase = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	camelCase  = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+)
+
+type linter struct {
+	config    *LintConfig
+	st        *SymbolTable
+	className string
+	issues    []*LintIssue
+
+	// states of the current subroutine
+	assigned map[string]bool     // locals assigned a value
+	created  map[string]TreeNode // locals assigned an object created by new, with the call
+	released map[string]bool     // locals disposed, returned or passed to others
+}
+
+func (l *linter) checkClass(class TreeNode) {
+	for _, n := range class.ChildNodes() {
+		switch n.Type() {
+		case ClassNameType:
+			l.className = n.Value()
+			if !pascalCase.MatchString(n.Value()) {
+				l.report(n.Pos(), "class-name", "class name %s should be PascalCase", n.Value())
+			}
+		case ClassVarDecType:
+			kind, _ := NewVarKind(n.ChildNodes()[0].Value())
+			l.defineVars(n, kind)
+		case SubroutineDecType:
+			l.checkSubroutine(n)
+		}
+	}
+}
+
+// defineVars defines the variables declared by a class var declaration, var declaration or parameter list.
+func (l *linter) defineVars(dec TreeNode, kind VarKind) {
+	typ := ""
+	for _, n := range dec.ChildNodes() {
+		switch n.Type() {
+		case TypeType:
+			typ = n.Value()
+		case VarNameType:
+			if !camelCase.MatchString(n.Value()) {
+				l.report(n.Pos(), "var-name", "variable name %s should be camelCase", n.Value())
+			}
+			if e := l.st.LookUp(n.Value()); e != nil && (kind == Argument || kind == Var) && (e.Kind == Field || e.Kind == Static) {
+				l.report(n.Pos(), "shadow", "%s shadows %s %s", n.Value(), kindName(e.Kind), e.Name)
+			}
+			l.st.Define(n.Value(), typ, kind) // ignore duplicates as the parser does
+		}
+	}
+}
+
+func (l *linter) checkSubroutine(dec TreeNode) {
+	l.st.ClearFuncTable()
+	l.assigned = make(map[string]bool)
+	l.created = make(map[string]TreeNode)
+	l.released = make(map[string]bool)
+
+	sig := newSubroutineSig(l.className, dec)
+	if lines := dec.End().Line - dec.Pos().Line + 1; lines > l.config.MaxSubroutineLines {
+		l.report(sig.Pos, "long-subroutine", "%s is %d lines long, more than %d", sig.FullName(), lines, l.config.MaxSubroutineLines)
+	}
+
+	for _, n := range dec.ChildNodes() {
+		switch n.Type() {
+		case ParameterListType:
+			l.defineVars(n, Argument)
+		case SubroutineBodyType:
+			for _, b := range n.ChildNodes() {
+				switch b.Type() {
+				case VarDecType:
+					l.defineVars(b, Var)
+				case StatementsType:
+					l.visit(b, 0)
+				}
+			}
+		}
+	}
+
+	for name, call := range l.created {
+		if !l.released[name] {
+			l.report(call.Pos(), "dispose", "%s created by %s is never disposed", name, callName(call))
+		}
+	}
+}
+
+func (l *linter) visit(node TreeNode, ifDepth int) {
+	switch node.Type() {
+	case IfStatementType:
+		ifDepth++
+		if ifDepth == l.config.MaxIfDepth+1 {
+			l.report(node.Pos(), "nested-if", "if statements are nested deeper than %d", l.config.MaxIfDepth)
+		}
+	case LetStatementType:
+		l.checkLet(node)
+	case ReturnStatementType:
+		if len(node.ChildNodes()) == 3 {
+			l.release(node.ChildNodes()[1])
+		}
+	case SubroutineCallType:
+		l.checkCall(node)
+	case ExpressionType:
+		l.checkComparison(node)
+	}
+
+	for _, n := range node.ChildNodes() {
+		if node.Type() == IfStatementType && n.Type() == IfStatementType {
+			l.visit(n, ifDepth-1) // else if is not nested
+			continue
+		}
+		l.visit(n, ifDepth)
+	}
+}
+
+func (l *linter) checkLet(node TreeNode) {
+	children := node.ChildNodes()
+	target := children[1]
+	local := target.Meta() != nil && target.Meta().Category == IdCatVar && target.Meta().SymbolInfo != nil
+	if !local {
+		l.release(letValue(node))
+		return
+	}
+
+	if isArrayLet(node) {
+		if target.Meta().SymbolInfo.Type == "Array" && !l.assigned[target.Value()] {
+			l.report(target.Pos(), "array-new", "element of %s is assigned before %s is created by Array.new", target.Value(), target.Value())
+			l.assigned[target.Value()] = true // report once
+		}
+		l.release(children[6])
+		return
+	}
+
+	l.assigned[target.Value()] = true
+	if call := newCall(children[3]); call != nil {
+		l.created[target.Value()] = call
+		delete(l.released, target.Value())
+	} else {
+		l.release(children[3])
+	}
+}
+
+func (l *linter) checkCall(node TreeNode) {
+	children := node.ChildNodes()
+	if len(children) > 2 && children[0].Type() == VarNameType && children[2].Value() == "dispose" {
+		l.released[children[0].Value()] = true
+	}
+	for _, n := range children {
+		if n.Type() != ExpressionListType {
+			continue
+		}
+		for _, e := range n.ChildNodes() {
+			if e.Type() == ExpressionType {
+				l.release(e)
+			}
+		}
+	}
+}
+
+func (l *linter) checkComparison(node TreeNode) {
+	children := node.ChildNodes()
+	for i := 1; i+1 < len(children); i += 2 {
+		op := children[i]
+		if op.Value() != "=" {
+			continue
+		}
+		for _, term := range []TreeNode{children[i-1], children[i+1]} {
+			c := term.ChildNodes()
+			if len(c) == 1 && c[0].Type() == KeywordConstantType && (c[0].Value() == "true" || c[0].Value() == "false") {
+				l.report(op.Pos(), "bool-compare", "comparison with %s, use the condition itself or ~", c[0].Value())
+			}
+		}
+	}
+}
+
+// release marks the local variable of the expression as released, if the expression is just a variable.
+func (l *linter) release(exp TreeNode) {
+	if len(exp.ChildNodes()) != 1 {
+		return
+	}
+	term := exp.ChildNodes()[0].ChildNodes()
+	if len(term) == 1 && term[0].Type() == VarNameType {
+		l.released[term[0].Value()] = true
+	}
+}
+
+func (l *linter) report(pos Pos, rule string, format string, a ...interface{}) {
+	if !l.config.enabled(rule) {
+		return
+	}
+	l.issues = append(l.issues, &LintIssue{
+		Pos:  pos,
+		Rule: rule,
+		Msg:  fmt.Sprintf(format, a...),
+	})
+}
+
+// newCall returns the subroutine call if the expression is just a call of new such as Foo.new(1).
+func newCall(exp TreeNode) TreeNode {
+	if len(exp.ChildNodes()) != 1 {
+		return nil
+	}
+	term := exp.ChildNodes()[0].ChildNodes()
+	if len(term) != 1 || term[0].Type() != SubroutineCallType {
+		return nil
+	}
+	call := term[0].ChildNodes()
+	if call[0].Type() != ClassNameType || call[2].Value() != "new" {
+		return nil
+	}
+	return term[0]
+}
+
+func callName(call TreeNode) string {
+	c := call.ChildNodes()
+	return fmt.Sprintf("%s.%s", c[0].Value(), c[2].Value())
+}
+
+func kindName(k VarKind) string {
+	if k == Static {
+		return "static variable"
+	}
+	return "field"
+}
@@ -0,0 +1,167 @@
+package jack
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		config string
+		want   []string
+	}{
+		{
+			name: "naming",
+			src: `class game { field int Score, high_score;
+  method void f(int X) { var int okName; return; } }`,
+			want: []string{
+				"1:7: class name game should be PascalCase (class-name)",
+				"1:24: variable name Score should be camelCase (var-name)",
+				"1:31: variable name high_score should be camelCase (var-name)",
+				"2:21: variable name X should be camelCase (var-name)",
+			},
+		},
+		{
+			name: "shadow",
+			src: `class Main { field int x; static int s;
+  method void f(int x) { var int s, t; return; } }`,
+			want: []string{
+				"2:21: x shadows field x (shadow)",
+				"2:34: s shadows static variable s (shadow)",
+			},
+		},
+		{
+			name: "long subroutine and nested if",
+			src: `class Main {
+  function void f() {
+    if (true) { if (true) { if (true) { if (true) { if (true) { return; } } } } }
+    return;
+  }
+}`,
+			config: `{"maxSubroutineLines": 2}`,
+			want: []string{
+				"2:17: Main.f is 4 lines long, more than 2 (long-subroutine)",
+				"3:41: if statements are nested deeper than 3 (nested-if)",
+			},
+		},
+		{
+			name: "array without new",
+			src: `class Main {
+  function void f(Array p) { var Array a, b; let p[0] = 1; let a[0] = 1; let a[1] = 2; let b = a; let b[0] = 1; return; }
+}`,
+			want: []string{
+				"2:64: element of a is assigned before a is created by Array.new (array-new)",
+			},
+		},
+		{
+			name: "dispose",
+			src: `class Main {
+  function Foo f() {
+    var Foo a, b, c, d, e;
+    let a = Foo.new();
+    let b = Foo.new();
+    let c = Foo.new();
+    let d = Foo.new();
+    let e = Foo.new();
+    do b.dispose();
+    do Main.g(c);
+    let a = d;
+    return e;
+  }
+}`,
+			want: []string{
+				"4:13: a created by Foo.new is never disposed (dispose)",
+			},
+		},
+		{
+			name: "bool compare",
+			src: `class Main {
+  function void f(boolean b) { if (b = true) { return; } while (false = (b = false)) { } return; }
+}`,
+			want: []string{
+				"2:38: comparison with true, use the condition itself or ~ (bool-compare)",
+				"2:71: comparison with false, use the condition itself or ~ (bool-compare)",
+				"2:76: comparison with false, use the condition itself or ~ (bool-compare)",
+			},
+		},
+		{
+			name:   "disabled rules",
+			src:    `class game { field int Score; method void f(boolean b) { if (b = true) { } return; } }`,
+			config: `{"rules": {"class-name": false, "var-name": false}}`,
+			want: []string{
+				"1:64: comparison with true, use the condition itself or ~ (bool-compare)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultLintConfig()
+			if tt.config != "" {
+				c, err := ParseLintConfig([]byte(tt.config))
+				if err != nil {
+					t.Fatal(err)
+				}
+				config = c
+			}
+			var got []string
+			for _, i := range Lint(parseForTest(t, tt.src, false), config) {
+				got = append(got, i.String())
+			}
+			// parseForTest names the file Main.jack
+			for i := range tt.want {
+				tt.want[i] = "Main.jack:" + tt.want[i]
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("Lint() diff (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseLintConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    *LintConfig
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			in:   `{}`,
+			want: DefaultLintConfig(),
+		},
+		{
+			name: "rules and limits",
+			in:   `{"rules": {"shadow": false}, "maxIfDepth": 5}`,
+			want: &LintConfig{
+				Rules:              map[string]bool{"shadow": false},
+				MaxSubroutineLines: 60,
+				MaxIfDepth:         5,
+			},
+		},
+		{
+			name:    "unknown rule",
+			in:      `{"rules": {"no-such-rule": false}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			in:      `{"rules": `,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLintConfig([]byte(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLintConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("ParseLintConfig() diff (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
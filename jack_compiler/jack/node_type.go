@@ -1,4 +1,4 @@
-package main
+package jack
 
 // NodeType represents both terminal and non-terminal symbols.
 // This is also used for tokenized symbols
@@ -15,6 +15,8 @@ const (
 	// non-terminal symbols
 	ClassType
 	ClassVarDecType
+	ConstDecType
+	EnumDecType
 	SubroutineDecType
 	ParameterListType
 	SubroutineBodyType
@@ -25,6 +27,11 @@ const (
 	ReturnStatementType
 	LetStatementType
 	DoStatementType
+	ForStatementType
+	BreakStatementType
+	ContinueStatementType
+	SwitchStatementType
+	SwitchCaseType
 	ExpressionType
 	TermType
 	ExpressionListType
@@ -57,6 +64,10 @@ func (typ NodeType) String() string {
 		return "ClassType"
 	case ClassVarDecType:
 		return "ClassVarDecType"
+	case ConstDecType:
+		return "ConstDecType"
+	case EnumDecType:
+		return "EnumDecType"
 	case SubroutineDecType:
 		return "SubroutineDecType"
 	case ParameterListType:
@@ -77,6 +88,16 @@ func (typ NodeType) String() string {
 		return "LetStatementType"
 	case DoStatementType:
 		return "DoStatementType"
+	case ForStatementType:
+		return "ForStatementType"
+	case BreakStatementType:
+		return "BreakStatementType"
+	case ContinueStatementType:
+		return "ContinueStatementType"
+	case SwitchStatementType:
+		return "SwitchStatementType"
+	case SwitchCaseType:
+		return "SwitchCaseType"
 	case ExpressionType:
 		return "ExpressionType"
 	case TermType:
@@ -0,0 +1,139 @@
+package jack
+
+const minInt = -32768
+
+// doubleTemp is the temp register to duplicate a value for doubling it.
+const doubleTemp = 0
+
+// operand is the compiled code of an expression or term.
+// The value of a constant and the last unary op applied are kept for the optimization.
+type operand struct {
+	code    []string
+	isConst bool
+	value   int
+	unary   string
+}
+
+func (c *Compiler) constOperand(i int) *operand {
+	return &operand{code: c.vmc.constant(i), isConst: true, value: i}
+}
+
+// unaryOperand applies op to o. With the optimization constants are folded and -(-x) and ~(~x) become x.
+func (c *Compiler) unaryOperand(op TreeNode, o *operand) (*operand, error) {
+	if c.optimize {
+		if o.isConst {
+			switch op.Value() {
+			case "-":
+				return c.constOperand(wrapInt(-o.value)), nil
+			case "~":
+				return c.constOperand(^o.value), nil
+			}
+		}
+		if o.unary == op.Value() {
+			return &operand{code: o.code[:len(o.code)-1]}, nil
+		}
+	}
+	codes, err := c.compile(op)
+	if err != nil {
+		return nil, err
+	}
+	return &operand{code: append(append([]string{}, o.code...), codes...), unary: op.Value()}, nil
+}
+
+// binaryOperand applies op to l and r. With the optimization constants are folded, +0, -0, *1 and /1 are removed
+// and multiplications by powers of two become additions.
+func (c *Compiler) binaryOperand(op TreeNode, l, r *operand) (*operand, error) {
+	if c.optimize {
+		if l.isConst && r.isConst {
+			if v, ok := foldBinary(op.Value(), l.value, r.value); ok {
+				return c.constOperand(v), nil
+			}
+		}
+		switch {
+		case (op.Value() == "+" || op.Value() == "-") && r.isConst && r.value == 0,
+			(op.Value() == "*" || op.Value() == "/") && r.isConst && r.value == 1:
+			return l, nil
+		case op.Value() == "+" && l.isConst && l.value == 0,
+			op.Value() == "*" && l.isConst && l.value == 1:
+			return r, nil
+		case op.Value() == "*" && r.isConst && powerOfTwo(r.value) > 0:
+			return c.double(l, powerOfTwo(r.value)), nil
+		case op.Value() == "*" && l.isConst && powerOfTwo(l.value) > 0:
+			return c.double(r, powerOfTwo(l.value)), nil
+		}
+	}
+	codes, err := c.compile(op)
+	if err != nil {
+		return nil, err
+	}
+	code := append(append(append([]string{}, l.code...), r.code...), codes...)
+	return &operand{code: code}, nil
+}
+
+// double multiplies o by 2^n adding it to itself n times.
+func (c *Compiler) double(o *operand, n int) *operand {
+	code := append([]string{}, o.code...)
+	for i := 0; i < n; i++ {
+		code = append(code,
+			c.vmc.pop("temp", doubleTemp),
+			c.vmc.push("temp", doubleTemp),
+			c.vmc.push("temp", doubleTemp),
+			c.vmc.add(),
+		)
+	}
+	return &operand{code: code}
+}
+
+// foldBinary computes l op r as the Hack machine does. It fails if the result depends on the OS,
+// that is division by zero or involving -32768, or op is unknown.
+func foldBinary(op string, l, r int) (int, bool) {
+	switch op {
+	case "+":
+		return wrapInt(l + r), true
+	case "-":
+		return wrapInt(l - r), true
+	case "*":
+		return wrapInt(l * r), true
+	case "/":
+		if r == 0 || l == minInt || r == minInt {
+			return 0, false
+		}
+		return l / r, true
+	case "&":
+		return l & r, true
+	case "|":
+		return l | r, true
+	case "<":
+		return boolInt(l < r), true
+	case ">":
+		return boolInt(l > r), true
+	case "=":
+		return boolInt(l == r), true
+	}
+	return 0, false
+}
+
+// wrapInt wraps i into 16 bit two's complement.
+func wrapInt(i int) int {
+	return int(int16(i))
+}
+
+func boolInt(b bool) int {
+	if b {
+		return -1
+	}
+	return 0
+}
+
+// powerOfTwo returns n if i is 2^n with n >= 1, otherwise 0.
+func powerOfTwo(i int) int {
+	n := 0
+	for i > 1 && i%2 == 0 {
+		i /= 2
+		n++
+	}
+	if i != 1 {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,74 @@
+package jack
+
+// osClasses is the signatures of the Jack OS classes declared in projects/12.
+// They are used unless the program defines the class itself.
+var osClasses = map[string][]*SubroutineSig{
+	"Array": {
+		{Kind: Function, Type: "Array", Name: "new", Params: []string{"int"}},
+		{Kind: Method, Type: "void", Name: "dispose"},
+	},
+	"Keyboard": {
+		{Kind: Function, Type: "void", Name: "init"},
+		{Kind: Function, Type: "char", Name: "keyPressed"},
+		{Kind: Function, Type: "char", Name: "readChar"},
+		{Kind: Function, Type: "String", Name: "readLine", Params: []string{"String"}},
+		{Kind: Function, Type: "int", Name: "readInt", Params: []string{"String"}},
+	},
+	"Math": {
+		{Kind: Function, Type: "void", Name: "init"},
+		{Kind: Function, Type: "int", Name: "abs", Params: []string{"int"}},
+		{Kind: Function, Type: "int", Name: "multiply", Params: []string{"int", "int"}},
+		{Kind: Function, Type: "int", Name: "divide", Params: []string{"int", "int"}},
+		{Kind: Function, Type: "int", Name: "sqrt", Params: []string{"int"}},
+		{Kind: Function, Type: "int", Name: "max", Params: []string{"int", "int"}},
+		{Kind: Function, Type: "int", Name: "min", Params: []string{"int", "int"}},
+	},
+	"Memory": {
+		{Kind: Function, Type: "void", Name: "init"},
+		{Kind: Function, Type: "int", Name: "peek", Params: []string{"int"}},
+		{Kind: Function, Type: "void", Name: "poke", Params: []string{"int", "int"}},
+		{Kind: Function, Type: "int", Name: "alloc", Params: []string{"int"}},
+		{Kind: Function, Type: "void", Name: "deAlloc", Params: []string{"Array"}},
+	},
+	"Output": {
+		{Kind: Function, Type: "void", Name: "init"},
+		{Kind: Function, Type: "void", Name: "initMap"},
+		{Kind: Function, Type: "void", Name: "create", Params: []string{"int", "int", "int", "int", "int", "int", "int", "int", "int", "int", "int", "int"}},
+		{Kind: Function, Type: "Array", Name: "getMap", Params: []string{"char"}},
+		{Kind: Function, Type: "void", Name: "moveCursor", Params: []string{"int", "int"}},
+		{Kind: Function, Type: "void", Name: "printChar", Params: []string{"char"}},
+		{Kind: Function, Type: "void", Name: "printString", Params: []string{"String"}},
+		{Kind: Function, Type: "void", Name: "printInt", Params: []string{"int"}},
+		{Kind: Function, Type: "void", Name: "println"},
+		{Kind: Function, Type: "void", Name: "backSpace"},
+	},
+	"Screen": {
+		{Kind: Function, Type: "void", Name: "init"},
+		{Kind: Function, Type: "void", Name: "clearScreen"},
+		{Kind: Function, Type: "void", Name: "setColor", Params: []string{"boolean"}},
+		{Kind: Function, Type: "void", Name: "drawPixel", Params: []string{"int", "int"}},
+		{Kind: Function, Type: "void", Name: "drawLine", Params: []string{"int", "int", "int", "int"}},
+		{Kind: Function, Type: "void", Name: "drawRectangle", Params: []string{"int", "int", "int", "int"}},
+		{Kind: Function, Type: "void", Name: "drawCircle", Params: []string{"int", "int", "int"}},
+	},
+	"String": {
+		{Kind: Constructor, Type: "String", Name: "new", Params: []string{"int"}},
+		{Kind: Method, Type: "void", Name: "dispose"},
+		{Kind: Method, Type: "int", Name: "length"},
+		{Kind: Method, Type: "char", Name: "charAt", Params: []string{"int"}},
+		{Kind: Method, Type: "void", Name: "setCharAt", Params: []string{"int", "char"}},
+		{Kind: Method, Type: "String", Name: "appendChar", Params: []string{"char"}},
+		{Kind: Method, Type: "void", Name: "eraseLastChar"},
+		{Kind: Method, Type: "int", Name: "intValue"},
+		{Kind: Method, Type: "void", Name: "setInt", Params: []string{"int"}},
+		{Kind: Function, Type: "char", Name: "newLine"},
+		{Kind: Function, Type: "char", Name: "backSpace"},
+		{Kind: Function, Type: "char", Name: "doubleQuote"},
+	},
+	"Sys": {
+		{Kind: Function, Type: "void", Name: "init"},
+		{Kind: Function, Type: "void", Name: "halt"},
+		{Kind: Function, Type: "void", Name: "wait", Params: []string{"int"}},
+		{Kind: Function, Type: "void", Name: "error", Params: []string{"int"}},
+	},
+}
@@ -0,0 +1,1945 @@
+package jack
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Parser struct {
+	symbolTable *SymbolTable
+	errs        SyntaxErrorList
+	loopDepth   int // number of enclosing while and for statements
+	extensions  bool
+	className   string
+}
+
+func NewParser() *Parser {
+	st := NewSymbolTable()
+	return &Parser{
+		symbolTable: st,
+	}
+}
+
+// SetExtensions makes the parser accept `else if` and the constant references `Class.NAME` of the language extensions.
+// The other extensions start with their own keywords, which the tokenizer reads only in the extension mode.
+func (p *Parser) SetExtensions(on bool) {
+	p.extensions = on
+}
+
+// Parse parses a class. Syntax errors are recovered at statement and declaration boundaries,
+// so it returns the partial tree without the broken parts along with a SyntaxErrorList of all errors.
+func (p *Parser) Parse(tokens []Token) (*InnerNode, error) {
+	p.errs = nil
+	res, rest, err := p.parseClass(TokenList(tokens))
+	if err != nil {
+		var se *SyntaxError
+		if !errors.As(err, &se) {
+			return nil, err
+		}
+		p.report(se)
+		res = nil
+	} else if len(rest) > 0 {
+		next, _ := rest.LookAt(0) // no error guaranteed
+		p.report(syntaxError(next, len(rest), "end of file"))
+	}
+	if len(p.errs) == 0 {
+		return res, nil
+	}
+
+	// end of file has no token, so point at the last one
+	for _, se := range p.errs {
+		if !se.Pos.IsValid() && len(tokens) > 0 {
+			se.Pos = tokens[len(tokens)-1].Pos()
+		}
+	}
+	return res, p.errs
+}
+
+func (p *Parser) parseClass(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewClassNode()
+	p.symbolTable.Clear()
+
+	// class keyword
+	mayClassKeyword, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseClass] %w", err)
+	}
+	if mayClassKeyword.Type() != KeywordType || mayClassKeyword.Value() != "class" {
+		return nil, nil, fmt.Errorf("[parseClass] %w", syntaxError(mayClassKeyword, len(rest)+1, "'class'"))
+	}
+	res.AppendChild(mayClassKeyword)
+
+	// class name
+	cn, rest, err := p.parseClassName(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseClass] %w", err)
+	}
+	res.AppendChild(cn)
+	if err := p.SetOneChildMeta(cn, res); err != nil {
+		return nil, nil, fmt.Errorf("[parseClass] %w", err)
+	}
+	p.className = cn.Value()
+
+	// open bracket
+	mayOpenBracket, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseClass] %w", err)
+	}
+	if mayOpenBracket.Type() != SymbolType || mayOpenBracket.Value() != "{" {
+		return nil, nil, fmt.Errorf("[parseClass] %w", syntaxError(mayOpenBracket, len(rest)+1, "'{'"))
+	}
+	res.AppendChild(mayOpenBracket)
+
+	// class var declaration
+	for true {
+		d, r, err := p.parseClassLevelDec(rest)
+		if err != nil {
+			if notStarted(err, rest) {
+				break
+			}
+			if rest, err = p.recover(err, rest, syncDeclaration); err != nil {
+				return nil, nil, fmt.Errorf("[parseClass] %w", err)
+			}
+			continue
+		}
+		res.AppendChild(d)
+		rest = r
+	}
+
+	// subroutine declaration
+	for true {
+		d, r, err := p.parseSubroutineDec(rest)
+		if err != nil {
+			if notStarted(err, rest) {
+				next, err := rest.LookAt(0)
+				if err != nil || isSymbol(next, "}") {
+					break
+				}
+				// a stray token which can not start a declaration
+				p.report(syntaxError(next, len(rest), "subroutine declaration or '}'"))
+				rest = syncDeclaration(rest[1:])
+				continue
+			}
+			if rest, err = p.recover(err, rest, syncDeclaration); err != nil {
+				return nil, nil, fmt.Errorf("[parseClass] %w", err)
+			}
+			continue
+		}
+		res.AppendChild(d)
+		rest = r
+	}
+
+	// close bracket
+	mayCloseBracket, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseClass] %w", err)
+	}
+	if mayCloseBracket.Type() != SymbolType || mayCloseBracket.Value() != "}" {
+		return nil, nil, fmt.Errorf("[parseClass] %w", syntaxError(mayCloseBracket, len(rest)+1, "declaration or '}'"))
+	}
+	res.AppendChild(mayCloseBracket)
+
+	return res, rest, nil
+}
+
+// parseClassLevelDec parses a class var declaration, or a const or enum declaration of the language extensions.
+func (p *Parser) parseClassLevelDec(tokens TokenList) (*InnerNode, TokenList, error) {
+	if next, err := tokens.LookAt(0); err == nil && next.Type() == KeywordType {
+		switch next.Value() {
+		case "const":
+			return p.parseConstDec(tokens)
+		case "enum":
+			return p.parseEnumDec(tokens)
+		}
+	}
+	return p.parseClassVarDec(tokens)
+}
+
+func (p *Parser) parseClassVarDec(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewClassVarDecNode()
+
+	// class var type
+	mayClassVarType, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseClassVarDec] %w", err)
+	}
+	if mayClassVarType.Type() != KeywordType || (mayClassVarType.Value() != "static" && mayClassVarType.Value() != "field") {
+		return nil, nil, fmt.Errorf("[parseClassVarDec] %w", syntaxError(mayClassVarType, len(rest)+1, "'static' or 'field'"))
+	}
+	res.AppendChild(mayClassVarType)
+
+	// type
+	typ, rest, err := p.parseType(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseClassVarDec] %w", err)
+	}
+	res.AppendChild(typ)
+
+	// var name
+	v, rest, err := p.parseVarName(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseClassVarDec] %w", err)
+	}
+	res.AppendChild(v)
+	kind, _ := NewVarKind(mayClassVarType.Value()) // no error guaranteed
+	p.symbolTable.Define(v.Value(), typ.Value(), kind)
+	if err := p.SetOneChildMeta(v, res); err != nil {
+		return nil, nil, fmt.Errorf("[parseClassVarDec] %w", err)
+	}
+
+	// following vars
+	for true {
+		// comma
+		mayComma, r, err := rest.PopNext()
+		if err != nil {
+			break
+		}
+		if mayComma.Type() != SymbolType || mayComma.Value() != "," {
+			break
+		}
+
+		// var
+		v, r, err := p.parseVarName(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseClassVarDec] %w", err)
+		}
+
+		res.AppendChild(mayComma)
+		res.AppendChild(v)
+		rest = r
+		p.symbolTable.Define(v.Value(), typ.Value(), kind)
+		if err := p.SetOneChildMeta(v, res); err != nil {
+			return nil, nil, fmt.Errorf("[parseClassVarDec] %w", err)
+		}
+	}
+
+	// semicolon
+	maySemicolon, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseClassVarDec] %w", err)
+	}
+	if maySemicolon.Type() != SymbolType || maySemicolon.Value() != ";" {
+		return nil, nil, fmt.Errorf("[parseClassVarDec] %w", syntaxError(maySemicolon, len(rest)+1, "',' or ';'"))
+	}
+	res.AppendChild(maySemicolon)
+
+	return res, rest, nil
+}
+
+// parseConstDec parses `const type name = constant;`. The constant is recorded as Class.name in the symbol table.
+func (p *Parser) parseConstDec(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewConstDecNode()
+
+	// const keyword
+	mayConstKeyword, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseConstDec] %w", err)
+	}
+	if mayConstKeyword.Type() != KeywordType || mayConstKeyword.Value() != "const" {
+		return nil, nil, fmt.Errorf("[parseConstDec] %w", syntaxError(mayConstKeyword, len(rest)+1, "'const'"))
+	}
+	res.AppendChild(mayConstKeyword)
+
+	// type
+	typ, r, err := p.parseType(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseConstDec] %w", err)
+	}
+	if !isPrimitive(typ.Value()) {
+		return nil, nil, fmt.Errorf("[parseConstDec] %w", syntaxError(typ.ChildNodes()[0], len(rest), "'int', 'char' or 'boolean'"))
+	}
+	res.AppendChild(typ)
+	rest = r
+
+	// name
+	v, rest, err := p.parseVarName(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseConstDec] %w", err)
+	}
+	res.AppendChild(v)
+
+	// equal
+	mayEqual, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseConstDec] %w", err)
+	}
+	if mayEqual.Type() != SymbolType || mayEqual.Value() != "=" {
+		return nil, nil, fmt.Errorf("[parseConstDec] %w", syntaxError(mayEqual, len(rest)+1, "'='"))
+	}
+	res.AppendChild(mayEqual)
+
+	// value
+	term, r, err := p.parseTerm(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseConstDec] %w", err)
+	}
+	value, ok := caseValue(term)
+	if !ok {
+		first, _ := rest.LookAt(0) // no error guaranteed
+		return nil, nil, fmt.Errorf("[parseConstDec] %w", syntaxError(first, len(rest), "integer, character or boolean constant"))
+	}
+	res.AppendChild(term)
+	rest = r
+
+	// semicolon
+	maySemicolon, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseConstDec] %w", err)
+	}
+	if maySemicolon.Type() != SymbolType || maySemicolon.Value() != ";" {
+		return nil, nil, fmt.Errorf("[parseConstDec] %w", syntaxError(maySemicolon, len(rest)+1, "';'"))
+	}
+	res.AppendChild(maySemicolon)
+
+	if err := p.defineConst(v, res, p.className, typ.Value(), value); err != nil {
+		return nil, nil, fmt.Errorf("[parseConstDec] %w", err)
+	}
+	return res, rest, nil
+}
+
+// parseEnumDec parses `enum Name { A, B, ... }`. The members are int constants numbered from 0,
+// recorded as Name.A and so on in the symbol table.
+func (p *Parser) parseEnumDec(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewEnumDecNode()
+
+	// enum keyword
+	mayEnumKeyword, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseEnumDec] %w", err)
+	}
+	if mayEnumKeyword.Type() != KeywordType || mayEnumKeyword.Value() != "enum" {
+		return nil, nil, fmt.Errorf("[parseEnumDec] %w", syntaxError(mayEnumKeyword, len(rest)+1, "'enum'"))
+	}
+	res.AppendChild(mayEnumKeyword)
+
+	// enum name
+	cn, rest, err := p.parseClassName(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseEnumDec] %w", err)
+	}
+	res.AppendChild(cn)
+	if err := p.SetOneChildMeta(cn, res); err != nil {
+		return nil, nil, fmt.Errorf("[parseEnumDec] %w", err)
+	}
+
+	// open bracket
+	mayOpenBracket, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseEnumDec] %w", err)
+	}
+	if mayOpenBracket.Type() != SymbolType || mayOpenBracket.Value() != "{" {
+		return nil, nil, fmt.Errorf("[parseEnumDec] %w", syntaxError(mayOpenBracket, len(rest)+1, "'{'"))
+	}
+	res.AppendChild(mayOpenBracket)
+
+	// members and close bracket, skipped to the close bracket on errors not to take it for the end of the class
+	r, err := p.parseEnumMembers(res, cn.Value(), rest)
+	if err != nil {
+		if r, err = p.recover(err, rest, skipBlock); err != nil {
+			return nil, nil, fmt.Errorf("[parseEnumDec] %w", err)
+		}
+	}
+	return res, r, nil
+}
+
+func (p *Parser) parseEnumMembers(res *InnerNode, enum string, tokens TokenList) (TokenList, error) {
+	rest := tokens
+	for i := 0; ; i++ {
+		v, r, err := p.parseVarName(rest)
+		if err != nil {
+			return nil, fmt.Errorf("[parseEnumMembers] %w", err)
+		}
+		res.AppendChild(v)
+		rest = r
+		if err := p.defineConst(v, res, enum, "int", i); err != nil {
+			return nil, fmt.Errorf("[parseEnumMembers] %w", err)
+		}
+
+		mayComma, err := rest.LookAt(0)
+		if err != nil {
+			return nil, fmt.Errorf("[parseEnumMembers] %w", err)
+		}
+		if mayComma.Type() != SymbolType || mayComma.Value() != "," {
+			break
+		}
+		res.AppendChild(mayComma)
+		rest = rest[1:]
+	}
+
+	mayCloseBracket, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, fmt.Errorf("[parseEnumMembers] %w", err)
+	}
+	if mayCloseBracket.Type() != SymbolType || mayCloseBracket.Value() != "}" {
+		return nil, fmt.Errorf("[parseEnumMembers] %w", syntaxError(mayCloseBracket, len(rest)+1, "',' or '}'"))
+	}
+	res.AppendChild(mayCloseBracket)
+
+	return rest, nil
+}
+
+// defineConst records the constant named v as scope.name and sets the meta of v.
+func (p *Parser) defineConst(v *OneChildNode, dec TreeNode, scope string, typ string, value int) error {
+	name := fmt.Sprintf("%s.%s", scope, v.Value())
+	p.symbolTable.DefineConst(name, typ, value) // ignore duplicates as Define does
+	return v.ChildNodes()[0].SetMeta(v.Type(), dec.Type(), p.symbolTable.LookUp(name))
+}
+
+func (p *Parser) parseType(tokens TokenList) (*OneChildNode, TokenList, error) {
+	res := NewTypeNode()
+
+	mayKw, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseType] %w", err)
+	}
+	if mayKw.Type() == KeywordType {
+		switch mayKw.Value() {
+		case "int", "char", "boolean":
+			res.AppendChild(mayKw)
+			return res, rest, nil
+		}
+		return nil, nil, fmt.Errorf("[parseType] %w", syntaxError(mayKw, len(tokens), "type"))
+	}
+
+	n, rest, err := p.parseClassName(tokens)
+	if err != nil {
+		if notStarted(err, tokens) {
+			return nil, nil, fmt.Errorf("[parseType] %w", syntaxError(mayKw, len(tokens), "type"))
+		}
+		return nil, nil, fmt.Errorf("[parseType] %w", err)
+	}
+	res.AppendChild(n)
+	if err := p.SetOneChildMeta(n, res); err != nil {
+		return nil, nil, fmt.Errorf("[parseType] %w", err)
+	}
+
+	return res, rest, nil
+}
+
+func (p *Parser) parseSubroutineDec(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewSubroutineDecNode()
+	p.symbolTable.ClearFuncTable()
+
+	// func type
+	mayFuncType, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineDec] %w", err)
+	}
+	if mayFuncType.Type() != KeywordType || (mayFuncType.Value() != "constructor" && mayFuncType.Value() != "function" && mayFuncType.Value() != "method") {
+		return nil, nil, fmt.Errorf("[parseSubroutineDec] %w", syntaxError(mayFuncType, len(rest)+1, "'constructor', 'function' or 'method'"))
+	}
+	res.AppendChild(mayFuncType)
+
+	// return value type
+	mayVoid, err := rest.LookAt(0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineDec] %w", err)
+	}
+	if mayVoid.Type() == KeywordType && mayVoid.Value() == "void" {
+		res.AppendChild(mayVoid)
+		_, r, err := rest.PopNext()
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseSubroutineDec] %w", err)
+		}
+		rest = r
+	} else {
+		typ, r, err := p.parseType(rest)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseSubroutineDec] %w", err)
+		}
+		res.AppendChild(typ)
+		rest = r
+	}
+
+	// subroutine name
+	sn, rest, err := p.parseSubroutineName(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineDec] %w", err)
+	}
+	res.AppendChild(sn)
+	if err := p.SetOneChildMeta(sn, res); err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineDec] %w", err)
+	}
+
+	// open paren
+	mayOpenParen, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineDec] %w", err)
+	}
+	if mayOpenParen.Type() != SymbolType || mayOpenParen.Value() != "(" {
+		return nil, nil, fmt.Errorf("[parseSubroutineDec] %w", syntaxError(mayOpenParen, len(rest)+1, "'('"))
+	}
+	res.AppendChild(mayOpenParen)
+
+	// parameter list
+	pl, rest, err := p.parseParameterList(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineDec] %w", err)
+	}
+	res.AppendChild(pl)
+
+	// close paren
+	mayCloseParen, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineDec] %w", err)
+	}
+	if mayCloseParen.Type() != SymbolType || mayCloseParen.Value() != ")" {
+		return nil, nil, fmt.Errorf("[parseSubroutineDec] %w", syntaxError(mayCloseParen, len(rest)+1, "')'"))
+	}
+	res.AppendChild(mayCloseParen)
+
+	// subroutine body
+	sb, rest, err := p.parseSubroutineBody(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineDec] %w", err)
+	}
+	res.AppendChild(sb)
+
+	return res, rest, nil
+}
+
+func (p *Parser) parseParameterList(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewParameterListNode()
+	rest := tokens
+
+	for true {
+		r := rest
+		// comma
+		if len(res.ChildNodes()) > 0 {
+			mayComma, rr, err := r.PopNext()
+			if err != nil {
+				break
+			}
+			if mayComma.Type() != SymbolType || mayComma.Value() != "," {
+				break
+			}
+			res.AppendChild(mayComma)
+			r = rr
+		}
+
+		// var
+		t, r, err := p.parseType(r)
+		if err != nil {
+			if notStarted(err, rest) {
+				break
+			}
+			return nil, nil, fmt.Errorf("[parseParameterList] %w", err)
+		}
+		n, r, err := p.parseVarName(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseParameterList] %w", err)
+		}
+		res.AppendChild(t)
+		res.AppendChild(n)
+		rest = r
+		p.symbolTable.Define(n.Value(), t.Value(), Argument)
+		if err := p.SetOneChildMeta(n, res); err != nil {
+			return nil, nil, fmt.Errorf("[parseClassVarDec] %w", err)
+		}
+	}
+
+	return res, rest, nil
+}
+
+func (p *Parser) parseSubroutineBody(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewSubroutineBodyNode()
+
+	// open bracket
+	mayOpenBracket, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineBody] %w", err)
+	}
+	if mayOpenBracket.Type() != SymbolType || mayOpenBracket.Value() != "{" {
+		return nil, nil, fmt.Errorf("[parseSubroutineBody] %w", syntaxError(mayOpenBracket, len(rest)+1, "'{'"))
+	}
+	res.AppendChild(mayOpenBracket)
+
+	// var declarations
+	for true {
+		v, r, err := p.parseVarDec(rest)
+		if err != nil {
+			if notStarted(err, rest) {
+				break
+			}
+			if rest, err = p.recover(err, rest, syncStatement); err != nil {
+				return nil, nil, fmt.Errorf("[parseSubroutineBody] %w", err)
+			}
+			continue
+		}
+		res.AppendChild(v)
+		rest = r
+	}
+
+	// statements
+	stms, rest, err := p.parseStatements(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineBody] %w", err)
+	}
+	res.AppendChild(stms)
+
+	// close bracket
+	mayCloseBracket, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineBody] %w", err)
+	}
+	if mayCloseBracket.Type() != SymbolType || mayCloseBracket.Value() != "}" {
+		return nil, nil, fmt.Errorf("[parseSubroutineBody] %w", syntaxError(mayCloseBracket, len(rest)+1, "statement or '}'"))
+	}
+	res.AppendChild(mayCloseBracket)
+
+	return res, rest, nil
+}
+
+func (p *Parser) parseVarDec(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewVarDecNode()
+
+	// var keyword
+	mayVarKeyword, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseVarDec] %w", err)
+	}
+	if mayVarKeyword.Type() != KeywordType || mayVarKeyword.Value() != "var" {
+		return nil, nil, fmt.Errorf("[parseVarDec] %w", syntaxError(mayVarKeyword, len(rest)+1, "'var'"))
+	}
+	res.AppendChild(mayVarKeyword)
+
+	// type
+	typ, rest, err := p.parseType(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseVarDec] %w", err)
+	}
+	res.AppendChild(typ)
+
+	// varName
+	v, rest, err := p.parseVarName(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseVarDec] %w", err)
+	}
+	res.AppendChild(v)
+	p.symbolTable.Define(v.Value(), typ.Value(), Var)
+	if err := p.SetOneChildMeta(v, res); err != nil {
+		return nil, nil, fmt.Errorf("[parseVarDec] %w", err)
+	}
+
+	// following vars
+	for true {
+		// comma
+		mayComma, r, err := rest.PopNext()
+		if err != nil {
+			break
+		}
+		if mayComma.Type() != SymbolType || mayComma.Value() != "," {
+			break
+		}
+
+		// var
+		v, r, err := p.parseVarName(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseVarDec] %w", err)
+		}
+
+		res.AppendChild(mayComma)
+		res.AppendChild(v)
+		rest = r
+		p.symbolTable.Define(v.Value(), typ.Value(), Var)
+		if err := p.SetOneChildMeta(v, res); err != nil {
+			return nil, nil, fmt.Errorf("[parseVarDec] %w", err)
+		}
+	}
+
+	// semicolon
+	maySemicolon, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, err
+	}
+	if maySemicolon.Type() != SymbolType || maySemicolon.Value() != ";" {
+		return nil, nil, fmt.Errorf("[parseVarDec] %w", syntaxError(maySemicolon, len(rest)+1, "',' or ';'"))
+	}
+	res.AppendChild(maySemicolon)
+
+	return res, rest, nil
+}
+
+func (p *Parser) parseClassName(tokens TokenList) (*OneChildNode, TokenList, error) {
+	next, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseClassName] %w", err)
+	}
+	if next.Type() != IdentifierType {
+		return nil, nil, fmt.Errorf("[parseClassName] %w", syntaxError(next, len(rest)+1, "class name"))
+	}
+	cn := NewClassNameNode()
+	cn.AppendChild(next)
+	return cn, rest, nil
+}
+
+func (p *Parser) parseSubroutineName(tokens TokenList) (*OneChildNode, TokenList, error) {
+	next, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineName] %w", err)
+	}
+	if next.Type() != IdentifierType {
+		return nil, nil, fmt.Errorf("[parseSubroutineName] %w", syntaxError(next, len(rest)+1, "subroutine name"))
+	}
+	sn := NewSubroutineNameNode()
+	sn.AppendChild(next)
+	return sn, rest, nil
+}
+
+func (p *Parser) parseVarName(tokens TokenList) (*OneChildNode, TokenList, error) {
+	next, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseVarName] %w", err)
+	}
+	if next.Type() != IdentifierType {
+		return nil, nil, fmt.Errorf("[parseVarName] %w", syntaxError(next, len(rest)+1, "variable name"))
+	}
+	vn := NewVarNameNode()
+	vn.AppendChild(next)
+	return vn, rest, nil
+}
+
+func (p *Parser) parseStatements(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewStatementsNode()
+	rest := tokens
+
+	for true {
+		n, r, err := p.parseStatement(rest)
+		if err != nil {
+			if notStarted(err, rest) {
+				next, err := rest.LookAt(0)
+				if err != nil || isSymbol(next, "}") || isSubroutineKeyword(next) {
+					break
+				}
+				// a stray token which can not start a statement
+				p.report(syntaxError(next, len(rest), "statement or '}'"))
+				rest = syncStatement(rest)
+				continue
+			}
+			if rest, err = p.recover(err, rest, syncStatement); err != nil {
+				return nil, nil, fmt.Errorf("[parseStatements] %w", err)
+			}
+			continue
+		}
+		res.AppendChild(n)
+		rest = r
+	}
+
+	return res, rest, nil
+}
+
+func (p *Parser) parseStatement(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewStatementNode()
+
+	if n, rest, err := p.parseLetStatement(tokens); err == nil {
+		res.AppendChild(n)
+		return res, rest, nil
+	} else if !notStarted(err, tokens) {
+		return nil, nil, fmt.Errorf("[parseStatement] %w", err)
+	}
+
+	if n, rest, err := p.parseIfStatement(tokens); err == nil {
+		res.AppendChild(n)
+		return res, rest, nil
+	} else if !notStarted(err, tokens) {
+		return nil, nil, fmt.Errorf("[parseStatement] %w", err)
+	}
+
+	if n, rest, err := p.parseWhileStatement(tokens); err == nil {
+		res.AppendChild(n)
+		return res, rest, nil
+	} else if !notStarted(err, tokens) {
+		return nil, nil, fmt.Errorf("[parseStatement] %w", err)
+	}
+
+	if n, rest, err := p.parseForStatement(tokens); err == nil {
+		res.AppendChild(n)
+		return res, rest, nil
+	} else if !notStarted(err, tokens) {
+		return nil, nil, fmt.Errorf("[parseStatement] %w", err)
+	}
+
+	if n, rest, err := p.parseSwitchStatement(tokens); err == nil {
+		res.AppendChild(n)
+		return res, rest, nil
+	} else if !notStarted(err, tokens) {
+		return nil, nil, fmt.Errorf("[parseStatement] %w", err)
+	}
+
+	if n, rest, err := p.parseLoopJump(tokens); err == nil {
+		res.AppendChild(n)
+		return res, rest, nil
+	} else if !notStarted(err, tokens) {
+		return nil, nil, fmt.Errorf("[parseStatement] %w", err)
+	}
+
+	if n, rest, err := p.parseDoStatement(tokens); err == nil {
+		res.AppendChild(n)
+		return res, rest, nil
+	} else if !notStarted(err, tokens) {
+		return nil, nil, fmt.Errorf("[parseStatement] %w", err)
+	}
+
+	if n, rest, err := p.parseReturnStatement(tokens); err == nil {
+		res.AppendChild(n)
+		return res, rest, nil
+	} else if !notStarted(err, tokens) {
+		return nil, nil, fmt.Errorf("[parseStatement] %w", err)
+	}
+
+	next, err := tokens.LookAt(0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseStatement] %w", err)
+	}
+	return nil, nil, fmt.Errorf("[parseStatement] %w", syntaxError(next, len(tokens), "statement"))
+}
+
+func (p *Parser) parseLetStatement(tokens TokenList) (*InnerNode, TokenList, error) {
+	res, rest, err := p.parseLetClause(tokens)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// semicolon
+	maySemicolon, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseLetStatement] %w", err)
+	}
+	if maySemicolon.Type() != SymbolType || maySemicolon.Value() != ";" {
+		return nil, nil, fmt.Errorf("[parseLetStatement] %w", syntaxError(maySemicolon, len(rest)+1, "';'"))
+	}
+	res.AppendChild(maySemicolon)
+
+	return res, rest, nil
+}
+
+// parseLetClause parses a let statement without the `;`, which is also the update of for statements.
+func (p *Parser) parseLetClause(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewLetStatementNode()
+
+	// let keyword
+	mayLetKeyword, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseLetStatement] %w", err)
+	}
+	if mayLetKeyword.Type() != KeywordType || mayLetKeyword.Value() != "let" {
+		return nil, nil, fmt.Errorf("[parseLetStatement] %w", syntaxError(mayLetKeyword, len(rest)+1, "'let'"))
+	}
+	res.AppendChild(mayLetKeyword)
+
+	// varName
+	vn, rest, err := p.parseVarName(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseLetStatement] %w", err)
+	}
+	res.AppendChild(vn)
+	if err := p.SetOneChildMeta(vn, res); err != nil {
+		return nil, nil, fmt.Errorf("[parseClassVarDec] %w", err)
+	}
+
+	// array index
+	mayOpenSqBracket, err := rest.LookAt(0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseLetStatement] %w", err)
+	}
+	if mayOpenSqBracket.Type() == SymbolType && mayOpenSqBracket.Value() == "[" {
+		openSqBracket, r, err := rest.PopNext()
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseLetStatement] %w", err)
+		}
+		res.AppendChild(openSqBracket)
+
+		ex, r, err := p.parseExpression(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseLetStatement] %w", err)
+		}
+		res.AppendChild(ex)
+
+		mayCloseSqBracket, r, err := r.PopNext()
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseLetStatement] %w", err)
+		}
+		if mayCloseSqBracket.Type() != SymbolType || mayCloseSqBracket.Value() != "]" {
+			return nil, nil, fmt.Errorf("[parseLetStatement] %w", syntaxError(mayCloseSqBracket, len(r)+1, "']'"))
+		}
+		res.AppendChild(mayCloseSqBracket)
+
+		rest = r
+	}
+
+	// equal
+	mayEqual, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, err
+	}
+	if mayEqual.Type() != SymbolType || mayEqual.Value() != "=" {
+		return nil, nil, fmt.Errorf("[parseLetStatement] %w", syntaxError(mayEqual, len(rest)+1, "'='"))
+	}
+	res.AppendChild(mayEqual)
+
+	// expression
+	ex, rest, err := p.parseExpression(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseLetStatement] %w", err)
+	}
+	res.AppendChild(ex)
+
+	return res, rest, nil
+}
+
+func (p *Parser) parseIfStatement(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewIfStatementNode()
+
+	// if keyword
+	mayIfKeyword, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", err)
+	}
+	if mayIfKeyword.Type() != KeywordType || mayIfKeyword.Value() != "if" {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", syntaxError(mayIfKeyword, len(rest)+1, "'if'"))
+	}
+	res.AppendChild(mayIfKeyword)
+
+	// open paren
+	mayOpenParen, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", err)
+	}
+	if mayOpenParen.Type() != SymbolType || mayOpenParen.Value() != "(" {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", syntaxError(mayOpenParen, len(rest)+1, "'('"))
+	}
+	res.AppendChild(mayOpenParen)
+
+	// expression
+	ex, rest, err := p.parseExpression(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", err)
+	}
+	res.AppendChild(ex)
+
+	// close paren
+	mayCloseParen, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", err)
+	}
+	if mayCloseParen.Type() != SymbolType || mayCloseParen.Value() != ")" {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", syntaxError(mayCloseParen, len(rest)+1, "')'"))
+	}
+	res.AppendChild(mayCloseParen)
+
+	// open bracket
+	mayOpenBracketIf, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", err)
+	}
+	if mayOpenBracketIf.Type() != SymbolType || mayOpenBracketIf.Value() != "{" {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", syntaxError(mayOpenBracketIf, len(rest)+1, "'{'"))
+	}
+	res.AppendChild(mayOpenBracketIf)
+
+	// statement
+	stIf, rest, err := p.parseStatements(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", err)
+	}
+	res.AppendChild(stIf)
+
+	// close bracket
+	mayCloseBracketIf, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", err)
+	}
+	if mayCloseBracketIf.Type() != SymbolType || mayCloseBracketIf.Value() != "}" {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", syntaxError(mayCloseBracketIf, len(rest)+1, "statement or '}'"))
+	}
+	res.AppendChild(mayCloseBracketIf)
+
+	// else keyword
+	mayElseKeyword, err := rest.LookAt(0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", err)
+	}
+	if mayElseKeyword.Type() != KeywordType || mayElseKeyword.Value() != "else" {
+		return res, rest, nil
+	}
+	_, rest, err = rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", err)
+	}
+	res.AppendChild(mayElseKeyword)
+
+	// else if
+	if next, err := rest.LookAt(0); err == nil && p.extensions && next.Type() == KeywordType && next.Value() == "if" {
+		elseIf, rest, err := p.parseIfStatement(rest)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseIfStatement] %w", err)
+		}
+		res.AppendChild(elseIf)
+		return res, rest, nil
+	}
+
+	// open bracket
+	mayOpenBracketElse, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", err)
+	}
+	if mayOpenBracketElse.Type() != SymbolType || mayOpenBracketElse.Value() != "{" {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", syntaxError(mayOpenBracketElse, len(rest)+1, "'{'"))
+	}
+	res.AppendChild(mayOpenBracketElse)
+
+	// statement
+	stElse, rest, err := p.parseStatements(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", err)
+	}
+	res.AppendChild(stElse)
+
+	// close bracket
+	mayCloseBracketElse, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", err)
+	}
+	if mayCloseBracketElse.Type() != SymbolType || mayCloseBracketElse.Value() != "}" {
+		return nil, nil, fmt.Errorf("[parseIfStatement] %w", syntaxError(mayCloseBracketElse, len(rest)+1, "statement or '}'"))
+	}
+	res.AppendChild(mayCloseBracketElse)
+
+	return res, rest, nil
+}
+
+func (p *Parser) parseWhileStatement(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewWhileStatementNode()
+
+	// while keyword
+	mayWhileKeyword, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseWhileStatement] %w", err)
+	}
+	if mayWhileKeyword.Type() != KeywordType || mayWhileKeyword.Value() != "while" {
+		return nil, nil, fmt.Errorf("[parseWhileStatement] %w", syntaxError(mayWhileKeyword, len(rest)+1, "'while'"))
+	}
+	res.AppendChild(mayWhileKeyword)
+
+	// open paren
+	mayOpenParen, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseWhileStatement] %w", err)
+	}
+	if mayOpenParen.Type() != SymbolType || mayOpenParen.Value() != "(" {
+		return nil, nil, fmt.Errorf("[parseWhileStatement] %w", syntaxError(mayOpenParen, len(rest)+1, "'('"))
+	}
+	res.AppendChild(mayOpenParen)
+
+	// expression
+	ex, rest, err := p.parseExpression(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseWhileStatement] %w", err)
+	}
+	res.AppendChild(ex)
+
+	// close paren
+	mayCloseParen, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseWhileStatement] %w", err)
+	}
+	if mayCloseParen.Type() != SymbolType || mayCloseParen.Value() != ")" {
+		return nil, nil, fmt.Errorf("[parseWhileStatement] %w", syntaxError(mayCloseParen, len(rest)+1, "')'"))
+	}
+	res.AppendChild(mayCloseParen)
+
+	// open bracket
+	mayOpenBracket, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseWhileStatement] %w", err)
+	}
+	if mayOpenBracket.Type() != SymbolType || mayOpenBracket.Value() != "{" {
+		return nil, nil, fmt.Errorf("[parseWhileStatement] %w", syntaxError(mayOpenBracket, len(rest)+1, "'{'"))
+	}
+	res.AppendChild(mayOpenBracket)
+
+	// statements
+	p.loopDepth++
+	st, rest, err := p.parseStatements(rest)
+	p.loopDepth--
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseWhileStatement] %w", err)
+	}
+	res.AppendChild(st)
+
+	// close bracket
+	mayCloseBracket, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseWhileStatement] %w", err)
+	}
+	if mayCloseBracket.Type() != SymbolType || mayCloseBracket.Value() != "}" {
+		return nil, nil, fmt.Errorf("[parseWhileStatement] %w", syntaxError(mayCloseBracket, len(rest)+1, "statement or '}'"))
+	}
+	res.AppendChild(mayCloseBracket)
+
+	return res, rest, nil
+}
+
+// parseForStatement parses `for (let ...; expression; let ...) { statements }` of the language extensions.
+func (p *Parser) parseForStatement(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewForStatementNode()
+
+	// for keyword
+	mayForKeyword, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", err)
+	}
+	if mayForKeyword.Type() != KeywordType || mayForKeyword.Value() != "for" {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", syntaxError(mayForKeyword, len(rest)+1, "'for'"))
+	}
+	res.AppendChild(mayForKeyword)
+
+	// open paren
+	mayOpenParen, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", err)
+	}
+	if mayOpenParen.Type() != SymbolType || mayOpenParen.Value() != "(" {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", syntaxError(mayOpenParen, len(rest)+1, "'('"))
+	}
+	res.AppendChild(mayOpenParen)
+
+	// initialization
+	init, rest, err := p.parseLetStatement(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", err)
+	}
+	res.AppendChild(init)
+
+	// condition
+	ex, rest, err := p.parseExpression(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", err)
+	}
+	res.AppendChild(ex)
+
+	maySemicolon, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", err)
+	}
+	if maySemicolon.Type() != SymbolType || maySemicolon.Value() != ";" {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", syntaxError(maySemicolon, len(rest)+1, "';'"))
+	}
+	res.AppendChild(maySemicolon)
+
+	// update
+	update, rest, err := p.parseLetClause(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", err)
+	}
+	res.AppendChild(update)
+
+	// close paren
+	mayCloseParen, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", err)
+	}
+	if mayCloseParen.Type() != SymbolType || mayCloseParen.Value() != ")" {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", syntaxError(mayCloseParen, len(rest)+1, "')'"))
+	}
+	res.AppendChild(mayCloseParen)
+
+	// open bracket
+	mayOpenBracket, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", err)
+	}
+	if mayOpenBracket.Type() != SymbolType || mayOpenBracket.Value() != "{" {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", syntaxError(mayOpenBracket, len(rest)+1, "'{'"))
+	}
+	res.AppendChild(mayOpenBracket)
+
+	// statements
+	p.loopDepth++
+	st, rest, err := p.parseStatements(rest)
+	p.loopDepth--
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", err)
+	}
+	res.AppendChild(st)
+
+	// close bracket
+	mayCloseBracket, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", err)
+	}
+	if mayCloseBracket.Type() != SymbolType || mayCloseBracket.Value() != "}" {
+		return nil, nil, fmt.Errorf("[parseForStatement] %w", syntaxError(mayCloseBracket, len(rest)+1, "statement or '}'"))
+	}
+	res.AppendChild(mayCloseBracket)
+
+	return res, rest, nil
+}
+
+// parseSwitchStatement parses `switch (expression) { case constant: { statements } ... default: { statements } }`
+// of the language extensions. The default case is optional and must be the last.
+func (p *Parser) parseSwitchStatement(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewSwitchStatementNode()
+
+	// switch keyword
+	maySwitchKeyword, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSwitchStatement] %w", err)
+	}
+	if maySwitchKeyword.Type() != KeywordType || maySwitchKeyword.Value() != "switch" {
+		return nil, nil, fmt.Errorf("[parseSwitchStatement] %w", syntaxError(maySwitchKeyword, len(rest)+1, "'switch'"))
+	}
+	res.AppendChild(maySwitchKeyword)
+
+	// open paren
+	mayOpenParen, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSwitchStatement] %w", err)
+	}
+	if mayOpenParen.Type() != SymbolType || mayOpenParen.Value() != "(" {
+		return nil, nil, fmt.Errorf("[parseSwitchStatement] %w", syntaxError(mayOpenParen, len(rest)+1, "'('"))
+	}
+	res.AppendChild(mayOpenParen)
+
+	// expression
+	ex, rest, err := p.parseExpression(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSwitchStatement] %w", err)
+	}
+	res.AppendChild(ex)
+
+	// close paren
+	mayCloseParen, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSwitchStatement] %w", err)
+	}
+	if mayCloseParen.Type() != SymbolType || mayCloseParen.Value() != ")" {
+		return nil, nil, fmt.Errorf("[parseSwitchStatement] %w", syntaxError(mayCloseParen, len(rest)+1, "')'"))
+	}
+	res.AppendChild(mayCloseParen)
+
+	// open bracket
+	mayOpenBracket, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSwitchStatement] %w", err)
+	}
+	if mayOpenBracket.Type() != SymbolType || mayOpenBracket.Value() != "{" {
+		return nil, nil, fmt.Errorf("[parseSwitchStatement] %w", syntaxError(mayOpenBracket, len(rest)+1, "'{'"))
+	}
+	res.AppendChild(mayOpenBracket)
+
+	// cases
+	hasDefault := false
+	for true {
+		next, err := rest.LookAt(0)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseSwitchStatement] %w", err)
+		}
+		if !isCaseKeyword(next) {
+			break
+		}
+		if hasDefault {
+			p.report(syntaxError(next, len(rest), "'}'"))
+		}
+		hasDefault = hasDefault || next.Value() == "default"
+		sc, r, err := p.parseSwitchCase(rest)
+		if err != nil {
+			if rest, err = p.recover(err, rest, syncCase); err != nil {
+				return nil, nil, fmt.Errorf("[parseSwitchStatement] %w", err)
+			}
+			continue
+		}
+		res.AppendChild(sc)
+		rest = r
+	}
+
+	// close bracket
+	mayCloseBracket, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSwitchStatement] %w", err)
+	}
+	if mayCloseBracket.Type() != SymbolType || mayCloseBracket.Value() != "}" {
+		return nil, nil, fmt.Errorf("[parseSwitchStatement] %w", syntaxError(mayCloseBracket, len(rest)+1, "'case', 'default' or '}'"))
+	}
+	res.AppendChild(mayCloseBracket)
+
+	return res, rest, nil
+}
+
+// parseSwitchCase parses `case constant: { statements }` or `default: { statements }`.
+func (p *Parser) parseSwitchCase(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewSwitchCaseNode()
+
+	// case or default keyword
+	mayKeyword, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSwitchCase] %w", err)
+	}
+	if !isCaseKeyword(mayKeyword) {
+		return nil, nil, fmt.Errorf("[parseSwitchCase] %w", syntaxError(mayKeyword, len(rest)+1, "'case' or 'default'"))
+	}
+	res.AppendChild(mayKeyword)
+
+	// constant
+	if mayKeyword.Value() == "case" {
+		term, r, err := p.parseTerm(rest)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseSwitchCase] %w", err)
+		}
+		if _, ok := caseValue(term); !ok && !isConstantRef(term) {
+			first, _ := rest.LookAt(0) // no error guaranteed
+			return nil, nil, fmt.Errorf("[parseSwitchCase] %w", syntaxError(first, len(rest), "integer, character or boolean constant"))
+		}
+		res.AppendChild(term)
+		rest = r
+	}
+
+	// colon
+	mayColon, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSwitchCase] %w", err)
+	}
+	if mayColon.Type() != SymbolType || mayColon.Value() != ":" {
+		return nil, nil, fmt.Errorf("[parseSwitchCase] %w", syntaxError(mayColon, len(rest)+1, "':'"))
+	}
+	res.AppendChild(mayColon)
+
+	// open bracket
+	mayOpenBracket, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSwitchCase] %w", err)
+	}
+	if mayOpenBracket.Type() != SymbolType || mayOpenBracket.Value() != "{" {
+		return nil, nil, fmt.Errorf("[parseSwitchCase] %w", syntaxError(mayOpenBracket, len(rest)+1, "'{'"))
+	}
+	res.AppendChild(mayOpenBracket)
+
+	// statements
+	st, rest, err := p.parseStatements(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSwitchCase] %w", err)
+	}
+	res.AppendChild(st)
+
+	// close bracket
+	mayCloseBracket, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSwitchCase] %w", err)
+	}
+	if mayCloseBracket.Type() != SymbolType || mayCloseBracket.Value() != "}" {
+		return nil, nil, fmt.Errorf("[parseSwitchCase] %w", syntaxError(mayCloseBracket, len(rest)+1, "statement or '}'"))
+	}
+	res.AppendChild(mayCloseBracket)
+
+	return res, rest, nil
+}
+
+// parseLoopJump parses `break;` and `continue;` of the language extensions, which must be in a loop.
+func (p *Parser) parseLoopJump(tokens TokenList) (*InnerNode, TokenList, error) {
+	mayKeyword, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseLoopJump] %w", err)
+	}
+	var res *InnerNode
+	switch {
+	case mayKeyword.Type() == KeywordType && mayKeyword.Value() == "break":
+		res = NewBreakStatementNode()
+	case mayKeyword.Type() == KeywordType && mayKeyword.Value() == "continue":
+		res = NewContinueStatementNode()
+	default:
+		return nil, nil, fmt.Errorf("[parseLoopJump] %w", syntaxError(mayKeyword, len(rest)+1, "'break' or 'continue'"))
+	}
+	if p.loopDepth == 0 {
+		se := syntaxError(mayKeyword, len(rest), "")
+		se.Found += " outside a loop"
+		return nil, nil, fmt.Errorf("[parseLoopJump] %w", se)
+	}
+	res.AppendChild(mayKeyword)
+
+	// semicolon
+	maySemicolon, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseLoopJump] %w", err)
+	}
+	if maySemicolon.Type() != SymbolType || maySemicolon.Value() != ";" {
+		return nil, nil, fmt.Errorf("[parseLoopJump] %w", syntaxError(maySemicolon, len(rest)+1, "';'"))
+	}
+	res.AppendChild(maySemicolon)
+
+	return res, rest, nil
+}
+
+func (p *Parser) parseDoStatement(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewDoStatementNode()
+
+	// do keyword
+	mayDoKeyword, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseDoStatement] %w", err)
+	}
+	if mayDoKeyword.Type() != KeywordType || mayDoKeyword.Value() != "do" {
+		return nil, nil, fmt.Errorf("[parseDoStatement] %w", syntaxError(mayDoKeyword, len(rest)+1, "'do'"))
+	}
+
+	// subroutine call
+	sc, rest, err := p.parseSubroutineCall(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseDoStatement] %w", err)
+	}
+
+	// semicolon
+	maySemicolon, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseDoStatement] %w", err)
+	}
+	if maySemicolon.Type() != SymbolType || maySemicolon.Value() != ";" {
+		return nil, nil, fmt.Errorf("[parseDoStatement] %w", syntaxError(maySemicolon, len(rest)+1, "';'"))
+	}
+
+	res.AppendChild(mayDoKeyword)
+	res.AppendChild(sc)
+	res.AppendChild(maySemicolon)
+
+	return res, rest, nil
+}
+
+func (p *Parser) parseReturnStatement(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewReturnStatementNode()
+
+	// return keyword
+	mayReturn, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseReturnStatement] %w", err)
+	}
+	if mayReturn.Type() != KeywordType || mayReturn.Value() != "return" {
+		return nil, nil, fmt.Errorf("[parseReturnStatement] %w", syntaxError(mayReturn, len(rest)+1, "'return'"))
+	}
+
+	// expression
+	ex, exRest, exErr := p.parseExpression(rest)
+	if exErr == nil {
+		rest = exRest
+	} else if !notStarted(exErr, rest) {
+		return nil, nil, fmt.Errorf("[parseReturnStatement] %w", exErr)
+	}
+
+	// `;` symbol
+	maySemicolon, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseReturnStatement] %w", err)
+	}
+	if maySemicolon.Type() != SymbolType || maySemicolon.Value() != ";" {
+		if exErr != nil {
+			return nil, nil, fmt.Errorf("[parseReturnStatement] %w", syntaxError(maySemicolon, len(rest)+1, "expression or ';'"))
+		}
+		return nil, nil, fmt.Errorf("[parseReturnStatement] %w", syntaxError(maySemicolon, len(rest)+1, "';'"))
+	}
+
+	res.AppendChild(mayReturn)
+	if exErr == nil {
+		res.AppendChild(ex)
+	}
+	res.AppendChild(maySemicolon)
+
+	return res, rest, nil
+}
+
+func (p *Parser) parseExpression(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewExpressionNode()
+
+	// first term
+	t1, rest, err := p.parseTerm(tokens)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseExpression] %w", err)
+	}
+	res.AppendChild(t1)
+
+	for true {
+		// op (optional)
+		mayOp, rest2, err := p.parseOp(rest)
+		if err != nil {
+			break
+		}
+		res.AppendChild(mayOp)
+
+		// second term if op exists
+		t2, rest3, err := p.parseTerm(rest2)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseExpression] %w", err)
+		}
+		res.AppendChild(t2)
+
+		rest = rest3
+	}
+
+	return res, rest, nil
+}
+
+func (p *Parser) parseTerm(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewTermNode()
+
+	next, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseTerm] %w", err)
+	}
+
+	// Int and string constant
+	switch next.Type() {
+	case IntConstType:
+		if next.Value() == minIntOperand {
+			return nil, nil, fmt.Errorf("[parseTerm] %w", syntaxError(next, len(tokens), fmt.Sprintf("integer constant in 0..%d", maxIntConst)))
+		}
+		res.AppendChild(next)
+		return res, rest, nil
+	case StrConstType:
+		res.AppendChild(next)
+		return res, rest, nil
+	}
+
+	// Keyword Constant
+	if kw, rest, err := p.parseKeywordConstant(tokens); err == nil {
+		res.AppendChild(kw)
+		return res, rest, nil
+	}
+
+	isSubCall := false
+	mayVarNameOrSub, err := tokens.LookAt(0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseTerm] %w", err)
+	}
+	if p.isConstantRef(tokens) {
+		return p.parseConstantRef(tokens)
+	}
+	if mayVarNameOrSub.Type() == IdentifierType {
+		mayFuncCall, err := tokens.LookAt(1)
+		if err == nil {
+			if mayFuncCall.Type() == SymbolType && (mayFuncCall.Value() == "(" || mayFuncCall.Value() == ".") {
+				isSubCall = true
+			}
+		}
+	}
+
+	// VarName
+	if mayVarNameOrSub.Type() == IdentifierType && !isSubCall {
+		if vn, rest, err := p.parseVarName(tokens); err == nil {
+			res.AppendChild(vn)
+			if err := p.SetOneChildMeta(vn, res); err != nil {
+				return nil, nil, fmt.Errorf("[parseClassVarDec] %w", err)
+			}
+
+			// VarName only
+			mayOpenSqBracket, err := rest.LookAt(0)
+			if err != nil {
+				return nil, nil, fmt.Errorf("[parseTerm] %w", err)
+			}
+			if mayOpenSqBracket.Type() != SymbolType || mayOpenSqBracket.Value() != "[" {
+				return res, rest, nil
+			}
+
+			// Array index
+			_, rest, err := rest.PopNext()
+			if err != nil {
+				return nil, nil, fmt.Errorf("[parseTerm] %w", err)
+			}
+			ex, rest, err := p.parseExpression(rest)
+			if err != nil {
+				return nil, nil, fmt.Errorf("[parseTerm] %w", err)
+			}
+			mayCloseSqBracket, rest, err := rest.PopNext()
+			if err != nil {
+				return nil, nil, fmt.Errorf("[parseTerm] %w", err)
+			}
+			if mayCloseSqBracket.Type() != SymbolType || mayCloseSqBracket.Value() != "]" {
+				return nil, nil, fmt.Errorf("[parseTerm] %w", syntaxError(mayCloseSqBracket, len(rest)+1, "']'"))
+			}
+			res.AppendChild(mayOpenSqBracket)
+			res.AppendChild(ex)
+			res.AppendChild(mayCloseSqBracket)
+			return res, rest, nil
+		}
+	}
+
+	// subroutineCall
+	if mayVarNameOrSub.Type() == IdentifierType && isSubCall {
+		sc, rest, err := p.parseSubroutineCall(tokens)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseTerm] %w", err)
+		}
+		res.AppendChild(sc)
+		return res, rest, nil
+	}
+
+	// expression enclosed in paren
+	mayOpenParen := next
+	if mayOpenParen.Type() == SymbolType && mayOpenParen.Value() == "(" {
+		ex, rest, err := p.parseExpression(rest)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseTerm] %w", err)
+		}
+		mayCloseParen, rest, err := rest.PopNext()
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseTerm] %w", err)
+		}
+		if mayCloseParen.Type() != SymbolType || mayCloseParen.Value() != ")" {
+			return nil, nil, fmt.Errorf("[parseTerm] %w", syntaxError(mayCloseParen, len(rest)+1, "')'"))
+		}
+		res.AppendChild(mayOpenParen)
+		res.AppendChild(ex)
+		res.AppendChild(mayCloseParen)
+		return res, rest, nil
+	}
+
+	// unaryOp
+	if uo, rest, err := p.parseUnaryOp(tokens); err == nil {
+		// -32768 is the only term with the constant 32768
+		if operand, after, err := rest.PopNext(); err == nil && uo.Value() == "-" && operand.Type() == IntConstType && operand.Value() == minIntOperand {
+			tm := NewTermNode()
+			tm.AppendChild(operand)
+			res.AppendChild(uo)
+			res.AppendChild(tm)
+			return res, after, nil
+		}
+
+		tm, rest, err := p.parseTerm(rest)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseTerm] %w", err)
+		}
+		res.AppendChild(uo)
+		res.AppendChild(tm)
+		return res, rest, nil
+	}
+
+	return nil, nil, fmt.Errorf("[parseTerm] %w", syntaxError(next, len(tokens), "expression"))
+}
+
+// isConstantRef reports whether the tokens start with `Class.NAME` not followed by `(`, which refers to a constant
+// in the extension mode.
+func (p *Parser) isConstantRef(tokens TokenList) bool {
+	if !p.extensions || len(tokens) < 3 {
+		return false
+	}
+	first, _ := tokens.LookAt(0) // no error guaranteed
+	dot, _ := tokens.LookAt(1)
+	name, _ := tokens.LookAt(2)
+	if first.Type() != IdentifierType || !isSymbol(dot, ".") || name.Type() != IdentifierType {
+		return false
+	}
+	if p.symbolTable.LookUp(first.Value()) != nil {
+		return false // a method call on a variable
+	}
+	next, err := tokens.LookAt(3)
+	return err != nil || !isSymbol(next, "(")
+}
+
+// parseConstantRef parses the term `Class.NAME` or `Enum.NAME`. Constants of the class being parsed are resolved
+// from the symbol table and the others are left for the compiler.
+func (p *Parser) parseConstantRef(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewTermNode()
+
+	cn, rest, err := p.parseClassName(tokens)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseConstantRef] %w", err)
+	}
+	if err := p.SetOneChildMeta(cn, res); err != nil {
+		return nil, nil, fmt.Errorf("[parseConstantRef] %w", err)
+	}
+	res.AppendChild(cn)
+
+	dot, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseConstantRef] %w", err)
+	}
+	res.AppendChild(dot)
+
+	v, rest, err := p.parseVarName(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseConstantRef] %w", err)
+	}
+	name := fmt.Sprintf("%s.%s", cn.Value(), v.Value())
+	if err := v.ChildNodes()[0].SetMeta(v.Type(), res.Type(), p.symbolTable.LookUp(name)); err != nil {
+		return nil, nil, fmt.Errorf("[parseConstantRef] %w", err)
+	}
+	res.AppendChild(v)
+
+	return res, rest, nil
+}
+
+// minIntOperand is the value of the constant in -32768, which is out of range anywhere else.
+const minIntOperand = "32768"
+
+func (p *Parser) parseSubroutineCall(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewSubroutineCallNode()
+
+	del, err := tokens.LookAt(1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineCall] %w", err)
+	}
+	if del.Type() != SymbolType || (del.Value() != "(" && del.Value() != ".") {
+		return nil, nil, fmt.Errorf("[parseSubroutineCall] %w", syntaxError(del, len(tokens)-1, "'(' or '.'"))
+	}
+
+	// method call
+	rest := tokens
+	if del.Value() == "." {
+		var classOrVarName *OneChildNode
+		var innerRest TokenList
+		n, _ := rest.LookAt(0) // no error guaranteed
+		if p.symbolTable.LookUp(n.Value()) != nil {
+			classOrVarName, innerRest, err = p.parseVarName(rest)
+			if err != nil {
+				return nil, nil, fmt.Errorf("[parseSubroutineCall] %w", err)
+			}
+		} else {
+			classOrVarName, innerRest, err = p.parseClassName(rest)
+			if err != nil {
+				return nil, nil, fmt.Errorf("[parseSubroutineCall] %w", err)
+			}
+		}
+		if err := p.SetOneChildMeta(classOrVarName, res); err != nil {
+			return nil, nil, fmt.Errorf("[parseClass] %w", err)
+		}
+		mayDot, innerRest2, err := innerRest.PopNext()
+		if err != nil {
+			return nil, nil, fmt.Errorf("[parseSubroutineCall] %w", err)
+		}
+		if mayDot.Type() != SymbolType || mayDot.Value() != "." {
+			return nil, nil, fmt.Errorf("[parseSubroutineCall] %w", syntaxError(mayDot, len(innerRest2)+1, "'.'"))
+		}
+		rest = innerRest2
+		res.AppendChild(classOrVarName)
+		res.AppendChild(mayDot)
+	}
+
+	// function call
+	sn, rest, err := p.parseSubroutineName(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineCall] %w", err)
+	}
+	if err := p.SetOneChildMeta(sn, res); err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineCall] %w", err)
+	}
+	mayOpenParen, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineCall] %w", err)
+	}
+	if mayOpenParen.Type() != SymbolType || mayOpenParen.Value() != "(" {
+		return nil, nil, fmt.Errorf("[parseSubroutineCall] %w", syntaxError(mayOpenParen, len(rest)+1, "'('"))
+	}
+	el, rest, err := p.parseExpressionList(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineCall] %w", err)
+	}
+	mayCloseParen, rest, err := rest.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseSubroutineCall] %w", err)
+	}
+	if mayCloseParen.Type() != SymbolType || mayCloseParen.Value() != ")" {
+		return nil, nil, fmt.Errorf("[parseSubroutineCall] %w", syntaxError(mayCloseParen, len(rest)+1, "')'"))
+	}
+	res.AppendChild(sn)
+	res.AppendChild(mayOpenParen)
+	res.AppendChild(el)
+	res.AppendChild(mayCloseParen)
+	return res, rest, nil
+}
+
+func (p *Parser) parseExpressionList(tokens TokenList) (*InnerNode, TokenList, error) {
+	res := NewExpressionListNode()
+	rest := tokens
+	for true {
+		r := rest
+		if len(res.ChildNodes()) > 0 {
+			mayComma, rr, err := r.PopNext()
+			if err != nil {
+				return nil, nil, fmt.Errorf("[parseExpressionList] %w", err)
+			}
+			if mayComma.Type() != SymbolType || mayComma.Value() != "," {
+				break
+			}
+			res.AppendChild(mayComma)
+			r = rr
+		}
+		ex, r, err := p.parseExpression(r)
+		if err != nil {
+			if notStarted(err, rest) {
+				break
+			}
+			return nil, nil, fmt.Errorf("[parseExpressionList] %w", err)
+		}
+		res.AppendChild(ex)
+		rest = r
+	}
+	return res, rest, nil
+}
+
+func (p *Parser) parseOp(tokens TokenList) (*OneChildNode, TokenList, error) {
+	cur, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseOp] %w", err)
+	}
+	if cur.Type() != SymbolType {
+		return nil, nil, fmt.Errorf("[parseOp] %w", syntaxError(cur, len(rest)+1, "operator"))
+	}
+	ok := false
+	switch cur.Value() {
+	case "+", "-", "*", "/", "&", "|", "<", ">", "=":
+		ok = true
+	}
+	if !ok {
+		return nil, nil, fmt.Errorf("[parseOp] %w", syntaxError(cur, len(rest)+1, "operator"))
+	}
+	on := NewOpNode()
+	on.AppendChild(cur)
+	return on, rest, nil
+}
+
+func (p *Parser) parseUnaryOp(tokens TokenList) (*OneChildNode, TokenList, error) {
+	cur, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseUnaryOp] %w", err)
+	}
+	if cur.Type() != SymbolType || (cur.Value() != "-" && cur.Value() != "~") {
+		return nil, nil, fmt.Errorf("[parseUnaryOp] %w", syntaxError(cur, len(rest)+1, "'-' or '~'"))
+	}
+	uon := NewUnaryOpNode()
+	uon.AppendChild(cur)
+	return uon, rest, nil
+}
+
+func (p *Parser) parseKeywordConstant(tokens TokenList) (*OneChildNode, TokenList, error) {
+	cur, rest, err := tokens.PopNext()
+	if err != nil {
+		return nil, nil, fmt.Errorf("[parseKeywordConstant] %w", err)
+	}
+	if cur.Type() != KeywordType || (cur.Value() != "true" && cur.Value() != "false" && cur.Value() != "null" && cur.Value() != "this") {
+		return nil, nil, fmt.Errorf("[parseKeywordConstant] %w", syntaxError(cur, len(rest)+1, "'true', 'false', 'null' or 'this'"))
+	}
+	kc := NewKeywordConstantNode()
+	kc.AppendChild(cur)
+	return kc, rest, nil
+}
+
+func (p *Parser) SetOneChildMeta(oc *OneChildNode, parent TreeNode) error {
+	return oc.ChildNodes()[0].SetMeta(oc.Type(), parent.Type(), p.symbolTable.LookUp(oc.Value()))
+}
+
+// report records a syntax error. Only the first error at the same token is kept.
+func (p *Parser) report(se *SyntaxError) {
+	if n := len(p.errs); n > 0 && p.errs[n-1].remaining == se.remaining {
+		return
+	}
+	p.errs = append(p.errs, se)
+}
+
+// recover records the syntax error err and returns the tokens to resume parsing from, skipped by sync from the error token.
+// Other errors can not be recovered and are returned as they are.
+func (p *Parser) recover(err error, tokens TokenList, sync func(TokenList) TokenList) (TokenList, error) {
+	var se *SyntaxError
+	if !errors.As(err, &se) {
+		return nil, err
+	}
+	p.report(se)
+	return sync(tokens[len(tokens)-se.remaining:]), nil
+}
+
+// syncStatement skips tokens to the next statement, which starts after `;` or a block, or at a statement keyword or `}`.
+func syncStatement(tokens TokenList) TokenList {
+	rest := tokens
+	for len(rest) > 0 {
+		next, r, _ := rest.PopNext() // no error guaranteed
+		switch {
+		case isSymbol(next, ";"):
+			return r
+		case isSymbol(next, "{"):
+			return skipBlock(r)
+		case isSymbol(next, "}"), isStatementKeyword(next):
+			return rest
+		}
+		rest = r
+	}
+	return rest
+}
+
+// syncCase skips tokens to the next case of a switch statement, or the `}` closing it.
+func syncCase(tokens TokenList) TokenList {
+	rest := tokens
+	for len(rest) > 0 {
+		next, r, _ := rest.PopNext() // no error guaranteed
+		switch {
+		case isSymbol(next, "{"):
+			r = skipBlock(r)
+		case isSymbol(next, "}"), isCaseKeyword(next):
+			return rest
+		}
+		rest = r
+	}
+	return rest
+}
+
+// syncDeclaration skips tokens to the next class var or subroutine declaration, or the `}` closing the class.
+func syncDeclaration(tokens TokenList) TokenList {
+	rest := tokens
+	for len(rest) > 0 {
+		next, r, _ := rest.PopNext() // no error guaranteed
+		switch {
+		case isSymbol(next, "{"):
+			r = skipBlock(r)
+		case isSymbol(next, "}"), isSubroutineKeyword(next):
+			return rest
+		case next.Type() == KeywordType && (next.Value() == "static" || next.Value() == "field" || next.Value() == "const" || next.Value() == "enum"):
+			return rest
+		}
+		rest = r
+	}
+	return rest
+}
+
+// skipBlock skips tokens to the `}` matching an already consumed `{`.
+func skipBlock(tokens TokenList) TokenList {
+	depth := 1
+	rest := tokens
+	for len(rest) > 0 {
+		next, r, _ := rest.PopNext() // no error guaranteed
+		rest = r
+		if isSymbol(next, "{") {
+			depth++
+		}
+		if isSymbol(next, "}") {
+			depth--
+			if depth == 0 {
+				break
+			}
+		}
+	}
+	return rest
+}
+
+func isSymbol(n TreeNode, v string) bool {
+	return n.Type() == SymbolType && n.Value() == v
+}
+
+func isStatementKeyword(n TreeNode) bool {
+	if n.Type() != KeywordType {
+		return false
+	}
+	switch n.Value() {
+	case "let", "if", "while", "do", "return", "for", "break", "continue", "switch":
+		return true
+	}
+	return false
+}
+
+func isCaseKeyword(n TreeNode) bool {
+	return n.Type() == KeywordType && (n.Value() == "case" || n.Value() == "default")
+}
+
+func isSubroutineKeyword(n TreeNode) bool {
+	if n.Type() != KeywordType {
+		return false
+	}
+	switch n.Value() {
+	case "constructor", "function", "method":
+		return true
+	}
+	return false
+}
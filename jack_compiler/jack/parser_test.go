@@ -1,6 +1,7 @@
-package main
+package jack
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -330,6 +331,32 @@ func TestParser_parseTerm(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "minimum int",
+			args: args{[]Token{
+				SymbolToken("-"),
+				IntConstToken(32768),
+				SymbolToken(";"),
+			}},
+			want: MockNodes([]TreeNode{
+				MockNodes([]TreeNode{AdaptTokenToNode(SymbolToken("-"))}, UnaryOpType, true),
+				MockNodes([]TreeNode{AdaptTokenToNode(IntConstToken(32768))}, TermType, false),
+			}, TermType, false),
+			want1: []Token{
+				SymbolToken(";"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "32768 without unary minus",
+			args: args{[]Token{
+				SymbolToken("~"),
+				IntConstToken(32768),
+			}},
+			want:    (*InnerNode)(nil),
+			want1:   nil,
+			wantErr: true,
+		},
 		{
 			name: "invalid",
 			args: args{[]Token{
@@ -1328,11 +1355,11 @@ func TestParser_parseStatements(t *testing.T) {
 		{
 			name: "no statement",
 			args: args{[]Token{
-				KeywordToken("return"),
+				SymbolToken("}"),
 			}},
 			want: MockNodes(nil, StatementsType, false),
 			want1: []Token{
-				KeywordToken("return"),
+				SymbolToken("}"),
 			},
 			wantErr: false,
 		},
@@ -1344,7 +1371,7 @@ func TestParser_parseStatements(t *testing.T) {
 				SymbolToken("="),
 				IntConstToken(100),
 				SymbolToken(";"),
-				KeywordToken("return"),
+				SymbolToken("}"),
 			}},
 			want: MockNodes([]TreeNode{
 				MockNodes([]TreeNode{
@@ -1360,7 +1387,7 @@ func TestParser_parseStatements(t *testing.T) {
 				}, StatementType, true),
 			}, StatementsType, false),
 			want1: []Token{
-				KeywordToken("return"),
+				SymbolToken("}"),
 			},
 			wantErr: false,
 		},
@@ -2111,3 +2138,131 @@ func TestParser_parseClass(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_Parse_syntaxError(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "missing expression",
+			src:  "class Main {\n  function void main() {\n    let x = ;\n  }\n}",
+			want: "Main.jack:3:13: expected expression, found ';'",
+		},
+		{
+			name: "missing term after op",
+			src:  "class Main {\n  function void main() {\n    let x = 1 + ;\n  }\n}",
+			want: "Main.jack:3:17: expected expression, found ';'",
+		},
+		{
+			name: "trailing comma in expression list",
+			src:  "class Main {\n  function void main() {\n    do f(1, );\n  }\n}",
+			want: "Main.jack:3:13: expected expression, found ')'",
+		},
+		{
+			name: "unknown statement",
+			src:  "class Main {\n  function void main() {\n    x = 1;\n  }\n}",
+			want: "Main.jack:3:5: expected statement or '}', found 'x'",
+		},
+		{
+			name: "missing close paren",
+			src:  "class Main {\n  function void main() {\n    if (x { return; }\n  }\n}",
+			want: "Main.jack:3:11: expected ')', found '{'",
+		},
+		{
+			name: "invalid return value",
+			src:  "class Main {\n  function void main() {\n    return );\n  }\n}",
+			want: "Main.jack:3:12: expected expression or ';', found ')'",
+		},
+		{
+			name: "trailing comma in parameter list",
+			src:  "class Main {\n  function void main(int a, ) {\n    return;\n  }\n}",
+			want: "Main.jack:2:29: expected type, found ')'",
+		},
+		{
+			name: "missing comma in class var declaration",
+			src:  "class Main {\n  field int a b;\n}",
+			want: "Main.jack:2:15: expected ',' or ';', found 'b'",
+		},
+		{
+			name: "unexpected end of file",
+			src:  "class Main {\n  function void main() {\n    return;",
+			want: "Main.jack:3:11: unexpected end of file",
+		},
+		{
+			name: "tokens after class",
+			src:  "class Main {\n}\n}",
+			want: "Main.jack:3:1: expected end of file, found '}'",
+		},
+		{
+			name: "errors in statements",
+			src:  "class Main {\n  function void main() {\n    let x = ;\n    if (x { let y = 1; }\n    do f(;\n    let z = 1;\n  }\n}",
+			want: "Main.jack:3:13: expected expression, found ';'\n" +
+				"Main.jack:4:11: expected ')', found '{'\n" +
+				"Main.jack:5:10: expected ')', found ';'",
+		},
+		{
+			name: "errors in nested statements",
+			src:  "class Main {\n  function void main() {\n    while (true) {\n      let x = ;\n      else;\n    }\n    return 1 +;\n  }\n}",
+			want: "Main.jack:4:15: expected expression, found ';'\n" +
+				"Main.jack:5:7: expected statement or '}', found 'else'\n" +
+				"Main.jack:7:15: expected expression, found ';'",
+		},
+		{
+			name: "errors in declarations",
+			src:  "class Main {\n  field int a b;\n  static x;\n  method void f(int a, ) { return; }\n  function g() { return; }\n  function void h() { var int; return; }\n}",
+			want: "Main.jack:2:15: expected ',' or ';', found 'b'\n" +
+				"Main.jack:3:11: expected variable name, found ';'\n" +
+				"Main.jack:4:24: expected type, found ')'\n" +
+				"Main.jack:5:13: expected subroutine name, found '('\n" +
+				"Main.jack:6:30: expected variable name, found ';'",
+		},
+		{
+			name: "break and continue outside loops",
+			src:  "class Main {\n  function void main() {\n    while (true) { if (true) { break; } }\n    continue;\n    for (let i = 0; i < 3; let i = i + 1) { break; }\n    break;\n  }\n}",
+			want: "Main.jack:4:5: unexpected 'continue' outside a loop\n" +
+				"Main.jack:6:5: unexpected 'break' outside a loop",
+		},
+		{
+			name: "switch cases",
+			src:  "class Main {\n  function void main(int x) {\n    switch (x) { case x: { } }\n    switch (x) { default: { } case 1: { } }\n    if (x) { } else if (x) { } else while (x) { }\n  }\n}",
+			want: "Main.jack:3:23: expected integer, character or boolean constant, found 'x'\n" +
+				"Main.jack:4:31: expected '}', found 'case'\n" +
+				"Main.jack:5:37: expected '{', found 'while'",
+		},
+		{
+			name: "const and enum declarations",
+			src:  "class Main {\n  const Array A = 1;\n  const int B = Main.C;\n  enum Dir { UP DOWN }\n  enum Empty { }\n  static int x;\n}",
+			want: "Main.jack:2:9: expected 'int', 'char' or 'boolean', found 'Array'\n" +
+				"Main.jack:3:17: expected integer, character or boolean constant, found 'Main'\n" +
+				"Main.jack:4:17: expected ',' or '}', found 'DOWN'\n" +
+				"Main.jack:5:16: expected variable name, found '}'",
+		},
+		{
+			name: "missing close bracket of subroutine",
+			src:  "class Main {\n  function void f() {\n    return;\n  function void g() {\n    return;\n  }\n}",
+			want: "Main.jack:4:3: expected statement or '}', found 'function'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenizer := NewTokenizer(strings.NewReader(tt.src), "Main.jack")
+			tokenizer.SetExtensions(true)
+			tokens, err := tokenizer.Tokenize()
+			if err != nil {
+				t.Fatal(err)
+			}
+			parser := NewParser()
+			parser.SetExtensions(true)
+			_, err = parser.Parse(tokens)
+			var l SyntaxErrorList
+			if !errors.As(err, &l) {
+				t.Fatalf("Parser.Parse() error = %v, want SyntaxErrorList", err)
+			}
+			if got := l.Error(); got != tt.want {
+				t.Errorf("Parser.Parse() error = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package jack
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pos is a position in a source file.
+// Line and Column start from 1, Column counts runes and Offset counts bytes from the beginning of the file.
+type Pos struct {
+	File   string
+	Line   int
+	Column int
+	Offset int
+}
+
+func (p Pos) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Pos) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// snippet returns the source line at pos with a caret pointing at the column.
+// Tabs before the column are kept so that the caret lines up with the line in any tab width.
+func snippet(src []byte, pos Pos) string {
+	lines := strings.Split(string(src), "\n")
+	if !pos.IsValid() || pos.Line > len(lines) {
+		return ""
+	}
+	line := strings.TrimRight(lines[pos.Line-1], "\r")
+	var caret []rune
+	for i, r := range []rune(line) {
+		if i >= pos.Column-1 {
+			break
+		}
+		if r == '\t' {
+			caret = append(caret, '\t')
+			continue
+		}
+		caret = append(caret, ' ')
+	}
+	return fmt.Sprintf("%s\n%s^", line, string(caret))
+}
@@ -0,0 +1,35 @@
+package jack
+
+import "testing"
+
+func TestSnippet(t *testing.T) {
+	src := []byte("class Main {\r\n\tlet x = ;\n}")
+	tests := []struct {
+		name string
+		pos  Pos
+		want string
+	}{
+		{
+			name: "caret keeps tab",
+			pos:  Pos{"Main.jack", 2, 10, 23},
+			want: "\tlet x = ;\n\t        ^",
+		},
+		{
+			name: "carriage return is trimmed",
+			pos:  Pos{"Main.jack", 1, 1, 0},
+			want: "class Main {\n^",
+		},
+		{
+			name: "invalid position",
+			pos:  Pos{},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snippet(src, tt.pos); got != tt.want {
+				t.Errorf("snippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
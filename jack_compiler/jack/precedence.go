@@ -0,0 +1,53 @@
+package jack
+
+// OpPrecedence returns how tightly a binary op binds in the precedence mode.
+// * and / bind tightest, then + and -, then the comparisons and & and | loosest.
+func OpPrecedence(op string) int {
+	switch op {
+	case "*", "/":
+		return 3
+	case "+", "-":
+		return 2
+	case "<", ">", "=":
+		return 1
+	}
+	return 0
+}
+
+// rootOp returns the index of the op applied last in exps, term (op term)*, or 0 for a single term.
+// It is the last op, or with precedence the last one binding loosest.
+func rootOp(exps []TreeNode, precedence bool) int {
+	root := 0
+	for i := 1; i+1 < len(exps); i += 2 {
+		if root == 0 || !precedence || OpPrecedence(exps[i].Value()) <= OpPrecedence(exps[root].Value()) {
+			root = i
+		}
+	}
+	return root
+}
+
+// CheckPrecedence warns about the expressions in the class whose meaning depends on the precedence mode,
+// that is where an op binds tighter than the op before it.
+func CheckPrecedence(class TreeNode) error {
+	var errs SemanticErrorList
+	var walk func(node TreeNode)
+	walk = func(node TreeNode) {
+		if node.Type() == ExpressionType {
+			children := node.ChildNodes()
+			for i := 3; i+1 < len(children); i += 2 {
+				prev, op := children[i-2], children[i]
+				if OpPrecedence(op.Value()) > OpPrecedence(prev.Value()) {
+					e := semanticError(op.Pos(), "%s after %s is applied left to right, but first with -precedence; add parentheses", op.Value(), prev.Value())
+					e.Warning = true
+					errs = append(errs, e)
+					break
+				}
+			}
+		}
+		for _, n := range node.ChildNodes() {
+			walk(n)
+		}
+	}
+	walk(class)
+	return errs.Err()
+}
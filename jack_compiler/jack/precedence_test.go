@@ -0,0 +1,36 @@
+package jack
+
+import "testing"
+
+func TestCheckPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "same meaning",
+			src:  "class Main {\n  function int f(int a) {\n    return a * 2 + 1 < 3 | (2 * a + 1);\n  }\n}",
+			want: "",
+		},
+		{
+			name: "different meaning",
+			src:  "class Main {\n  function int f(int a) {\n    if (a < a + 1) { return 1 + a * 2 - 3; }\n    return Main.f(a & a = 0);\n  }\n}",
+			want: "Main.jack:3:15: warning: + after < is applied left to right, but first with -precedence; add parentheses\n" +
+				"Main.jack:3:35: warning: * after + is applied left to right, but first with -precedence; add parentheses\n" +
+				"Main.jack:4:25: warning: = after & is applied left to right, but first with -precedence; add parentheses",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parseForTest(t, tt.src, false)
+			got := ""
+			if err := CheckPrecedence(tree); err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("CheckPrecedence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
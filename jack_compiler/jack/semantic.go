@@ -0,0 +1,422 @@
+package jack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SubroutineSig is the signature of a subroutine.
+type SubroutineSig struct {
+	Class  string
+	Kind   funcKind
+	Type   string
+	Name   string
+	Params []string
+	Pos    Pos
+}
+
+func (s *SubroutineSig) FullName() string {
+	return fmt.Sprintf("%s.%s", s.Class, s.Name)
+}
+
+func newSubroutineSig(class string, dec TreeNode) *SubroutineSig {
+	sig := &SubroutineSig{Class: class}
+	for i, node := range dec.ChildNodes() {
+		switch {
+		case i == 0:
+			sig.Kind = newFuncKind(node.Value())
+		case i == 1:
+			sig.Type = node.Value()
+		case node.Type() == SubroutineNameType:
+			sig.Name = node.Value()
+			sig.Pos = node.Pos()
+		case node.Type() == ParameterListType:
+			for _, p := range node.ChildNodes() {
+				if p.Type() == TypeType {
+					sig.Params = append(sig.Params, p.Value())
+				}
+			}
+		}
+	}
+	return sig
+}
+
+// ClassSig is the signatures of the subroutines and the constants of a class.
+// An enum of the language extensions is a class with its members as constants.
+type ClassSig struct {
+	Name        string
+	Subroutines map[string]*SubroutineSig
+	Constants   map[string]*SymbolInfo
+	Pos         Pos
+	os          bool
+}
+
+func newClassSig() *ClassSig {
+	return &ClassSig{
+		Subroutines: make(map[string]*SubroutineSig),
+		Constants:   make(map[string]*SymbolInfo),
+	}
+}
+
+// Program is the signatures of all classes compiled together, including the OS classes.
+type Program struct {
+	Classes map[string]*ClassSig
+	// Precedence makes TypeCheck apply binary ops by precedence like Compiler.SetPrecedence.
+	Precedence bool
+}
+
+func NewProgram() *Program {
+	p := &Program{
+		Classes: make(map[string]*ClassSig),
+	}
+	for name, subs := range osClasses {
+		cs := &ClassSig{
+			Name:        name,
+			Subroutines: make(map[string]*SubroutineSig),
+			os:          true,
+		}
+		for _, s := range subs {
+			sig := *s
+			sig.Class = name
+			cs.Subroutines[sig.Name] = &sig
+		}
+		p.Classes[name] = cs
+	}
+	return p
+}
+
+// AddClass collects the signatures and the constants of the class and its enums.
+// A class of the program replaces the OS class of the same name.
+func (p *Program) AddClass(class TreeNode) error {
+	var errs SemanticErrorList
+	cs := newClassSig()
+	var enums []*ClassSig
+	for _, node := range class.ChildNodes() {
+		switch node.Type() {
+		case ClassNameType:
+			cs.Name = node.Value()
+			cs.Pos = node.Pos()
+		case ConstDecType:
+			errs = append(errs, addConstant(cs, node.ChildNodes()[2])...)
+		case EnumDecType:
+			enum := newClassSig()
+			for _, n := range node.ChildNodes() {
+				switch n.Type() {
+				case ClassNameType:
+					enum.Name = n.Value()
+					enum.Pos = n.Pos()
+				case VarNameType:
+					errs = append(errs, addConstant(enum, n)...)
+				}
+			}
+			enums = append(enums, enum)
+		case SubroutineDecType:
+			sig := newSubroutineSig(cs.Name, node)
+			if _, ok := cs.Subroutines[sig.Name]; ok {
+				errs = append(errs, semanticError(sig.Pos, "duplicate subroutine %s", sig.FullName()))
+				continue
+			}
+			cs.Subroutines[sig.Name] = sig
+		}
+	}
+
+	for _, c := range append([]*ClassSig{cs}, enums...) {
+		if prev, ok := p.Classes[c.Name]; ok && !prev.os {
+			errs = append(errs, semanticError(c.Pos, "duplicate class %s", c.Name))
+		} else {
+			p.Classes[c.Name] = c
+		}
+	}
+
+	return errs.Err()
+}
+
+func addConstant(cs *ClassSig, varName TreeNode) SemanticErrorList {
+	if _, ok := cs.Constants[varName.Value()]; ok {
+		return SemanticErrorList{semanticError(varName.Pos(), "duplicate constant %s.%s", cs.Name, varName.Value())}
+	}
+	cs.Constants[varName.Value()] = varName.Meta().SymbolInfo
+	return nil
+}
+
+// Constants returns the values of the constants of all classes keyed by Class.NAME for Compiler.SetConstants.
+func (p *Program) Constants() map[string]int {
+	res := make(map[string]int)
+	for _, cs := range p.Classes {
+		for name, s := range cs.Constants {
+			res[fmt.Sprintf("%s.%s", cs.Name, name)] = s.Value
+		}
+	}
+	return res
+}
+
+// constant returns the constant which the term `Class.NAME` refers to, or nil if it is not defined.
+func (p *Program) constant(term TreeNode) *SymbolInfo {
+	if !isConstantRef(term) {
+		return nil
+	}
+	children := term.ChildNodes()
+	if cs, ok := p.Classes[children[0].Value()]; ok {
+		return cs.Constants[children[2].Value()]
+	}
+	return nil
+}
+
+// callee returns the signature of the subroutine called in the class, or nil if it is not defined.
+func (p *Program) callee(className string, call TreeNode) *SubroutineSig {
+	for _, n := range call.ChildNodes() {
+		switch n.Type() {
+		case ClassNameType:
+			className = n.Value()
+		case VarNameType:
+			className = varType(n)
+		case SubroutineNameType:
+			if cs, ok := p.Classes[className]; ok {
+				return cs.Subroutines[n.Value()]
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// Check checks that the types, variables and subroutine calls in the class refer to defined ones.
+// All classes of the program must be added before.
+func (p *Program) Check(class TreeNode) error {
+	c := &semanticChecker{prog: p}
+	c.check(class)
+	return c.errs.Err()
+}
+
+type semanticChecker struct {
+	prog      *Program
+	className string
+	sub       *SubroutineSig
+	errs      SemanticErrorList
+}
+
+func (c *semanticChecker) check(node TreeNode) {
+	switch node.Type() {
+	case ClassType:
+		for _, n := range node.ChildNodes() {
+			if n.Type() == ClassNameType {
+				c.className = n.Value()
+			}
+		}
+	case SubroutineDecType:
+		c.sub = newSubroutineSig(c.className, node)
+	case TypeType:
+		c.checkType(node)
+		return
+	case VarNameType:
+		c.checkVar(node)
+		return
+	case SubroutineCallType:
+		c.checkCall(node)
+	case SwitchStatementType:
+		c.checkSwitch(node)
+	case TermType:
+		if isConstantRef(node) {
+			c.checkConstant(node)
+			return
+		}
+	}
+
+	for _, n := range node.ChildNodes() {
+		c.check(n)
+	}
+}
+
+func (c *semanticChecker) checkConstant(term TreeNode) {
+	children := term.ChildNodes()
+	if _, ok := c.prog.Classes[children[0].Value()]; !ok {
+		c.errorf(children[0].Pos(), "undefined class %s", children[0].Value())
+		return
+	}
+	if c.prog.constant(term) == nil {
+		c.errorf(children[2].Pos(), "undefined constant %s.%s", children[0].Value(), children[2].Value())
+	}
+}
+
+func (c *semanticChecker) checkSwitch(node TreeNode) {
+	seen := make(map[int]bool)
+	for _, sc := range node.ChildNodes() {
+		if sc.Type() != SwitchCaseType || sc.ChildNodes()[0].Value() != "case" {
+			continue
+		}
+		term := sc.ChildNodes()[1]
+		v, ok := caseValue(term)
+		if s := c.prog.constant(term); !ok && s != nil {
+			v, ok = s.Value, true
+		}
+		if !ok {
+			continue // reported by checkConstant
+		}
+		if seen[v] {
+			c.errorf(term.Pos(), "duplicate case %d", v)
+		}
+		seen[v] = true
+	}
+}
+
+func (c *semanticChecker) checkType(node TreeNode) {
+	t := node.ChildNodes()[0]
+	if t.Type() != ClassNameType {
+		return
+	}
+	if _, ok := c.prog.Classes[t.Value()]; !ok {
+		c.errorf(t.Pos(), "undefined class %s", t.Value())
+	}
+}
+
+func (c *semanticChecker) checkVar(node TreeNode) {
+	meta := node.Meta()
+	if meta == nil || meta.SymbolInfo == nil {
+		c.errorf(node.Pos(), "undefined variable %s", node.Value())
+		return
+	}
+	if meta.Category == IdCatField && c.sub != nil && c.sub.Kind == Function {
+		c.errorf(node.Pos(), "field %s used in function %s", node.Value(), c.sub.FullName())
+	}
+}
+
+func (c *semanticChecker) checkCall(node TreeNode) {
+	var className string
+	var subName TreeNode
+	onObject := true
+	unqualified := false
+	args := 0
+
+	for _, n := range node.ChildNodes() {
+		switch n.Type() {
+		case ClassNameType:
+			className = n.Value()
+			onObject = false
+			if _, ok := c.prog.Classes[className]; !ok {
+				c.errorf(n.Pos(), "undefined class %s", className)
+				return
+			}
+		case VarNameType:
+			if n.Meta() == nil || n.Meta().SymbolInfo == nil {
+				return // reported by checkVar
+			}
+			className = n.Meta().SymbolInfo.Type
+			switch className {
+			case "int", "char", "boolean":
+				c.errorf(n.Pos(), "%s of type %s has no subroutines", n.Value(), className)
+				return
+			}
+			if _, ok := c.prog.Classes[className]; !ok {
+				return // reported at the declaration of the variable
+			}
+		case SubroutineNameType:
+			subName = n
+			if className == "" {
+				className = c.className
+				unqualified = true
+			}
+		case ExpressionListType:
+			for _, e := range n.ChildNodes() {
+				if e.Type() == ExpressionType {
+					args++
+				}
+			}
+		}
+	}
+
+	sig, ok := c.prog.Classes[className].Subroutines[subName.Value()]
+	if !ok {
+		c.errorf(subName.Pos(), "undefined subroutine %s.%s", className, subName.Value())
+		return
+	}
+	kind := strings.ToLower(sig.Kind.String())
+	if onObject && sig.Kind != Method {
+		c.errorf(subName.Pos(), "%s %s called as a method", kind, sig.FullName())
+		return
+	}
+	if !onObject && sig.Kind == Method {
+		c.errorf(subName.Pos(), "method %s called as a function", sig.FullName())
+		return
+	}
+	if unqualified && sig.Kind == Method && c.sub != nil && c.sub.Kind == Function {
+		c.errorf(subName.Pos(), "method %s called from function %s", sig.FullName(), c.sub.FullName())
+	}
+	if args != len(sig.Params) {
+		c.errorf(subName.Pos(), "%s %s takes %d arguments, got %d", kind, sig.FullName(), len(sig.Params), args)
+	}
+}
+
+func (c *semanticChecker) errorf(pos Pos, format string, a ...interface{}) {
+	c.errs = append(c.errs, semanticError(pos, format, a...))
+}
+
+// SemanticError is an error in a syntactically valid program, such as a reference to an undefined name.
+// A warning does not stop the compilation.
+type SemanticError struct {
+	Pos     Pos
+	Msg     string
+	Warning bool
+}
+
+func semanticError(pos Pos, format string, a ...interface{}) *SemanticError {
+	return &SemanticError{
+		Pos: pos,
+		Msg: fmt.Sprintf(format, a...),
+	}
+}
+
+func (e *SemanticError) Error() string {
+	if e.Warning {
+		return fmt.Sprintf("%v: warning: %s", e.Pos, e.Msg)
+	}
+	return fmt.Sprintf("%v: %s", e.Pos, e.Msg)
+}
+
+// Snippet shows where the problem is in src.
+func (e *SemanticError) Snippet(src []byte) string {
+	return snippet(src, e.Pos)
+}
+
+// SemanticErrorList is a list of semantic errors.
+type SemanticErrorList []*SemanticError
+
+func (l SemanticErrorList) Error() string {
+	var msgs []string
+	for _, e := range l {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Sort sorts the list by file, line and column, keeping the order of the errors at the same position.
+func (l SemanticErrorList) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		a, b := l[i].Pos, l[j].Pos
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+// HasError reports whether the list has an error other than warnings.
+func (l SemanticErrorList) HasError() bool {
+	for _, e := range l {
+		if !e.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns nil if the list is empty.
+func (l SemanticErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
@@ -0,0 +1,116 @@
+package jack
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProgram_Check(t *testing.T) {
+	foo := "class Foo {\n  field int x;\n  constructor Foo new() { return this; }\n  method int get(int a) { return x; }\n  function void f() { return; }\n}"
+	tests := []struct {
+		name string
+		main string
+		want string
+	}{
+		{
+			name: "valid",
+			main: "class Main {\n  function void main() {\n    var Foo foo;\n    let foo = Foo.new();\n    do foo.get(1);\n    do Foo.f();\n    do Output.printInt(Math.max(1, 2));\n    return;\n  }\n}",
+			want: "",
+		},
+		{
+			name: "undefined class",
+			main: "class Main {\n  function void main() {\n    var Bar bar;\n    do Bar.f();\n    return;\n  }\n}",
+			want: "Main.jack:3:9: undefined class Bar\n" +
+				"Main.jack:4:8: undefined class Bar",
+		},
+		{
+			name: "undefined subroutine",
+			main: "class Main {\n  function void main() {\n    do Foo.g();\n    do Output.print(1);\n    do h();\n    return;\n  }\n}",
+			want: "Main.jack:3:12: undefined subroutine Foo.g\n" +
+				"Main.jack:4:15: undefined subroutine Output.print\n" +
+				"Main.jack:5:8: undefined subroutine Main.h",
+		},
+		{
+			name: "arity mismatch",
+			main: "class Main {\n  function void main() {\n    var Foo foo;\n    do foo.get();\n    do Math.max(1);\n    return;\n  }\n}",
+			want: "Main.jack:4:12: method Foo.get takes 1 arguments, got 0\n" +
+				"Main.jack:5:13: function Math.max takes 2 arguments, got 1",
+		},
+		{
+			name: "call form mismatch",
+			main: "class Main {\n  method void m() { return; }\n  function void main() {\n    var Foo foo;\n    do Foo.get(1);\n    do foo.f();\n    do m();\n    return;\n  }\n}",
+			want: "Main.jack:5:12: method Foo.get called as a function\n" +
+				"Main.jack:6:12: function Foo.f called as a method\n" +
+				"Main.jack:7:8: method Main.m called from function Main.main",
+		},
+		{
+			name: "unknown variables",
+			main: "class Main {\n  field int x;\n  function void main() {\n    var int i;\n    let y = 1;\n    let i = z[x];\n    do i.f();\n    return;\n  }\n}",
+			want: "Main.jack:5:9: undefined variable y\n" +
+				"Main.jack:6:13: undefined variable z\n" +
+				"Main.jack:6:15: field x used in function Main.main\n" +
+				"Main.jack:7:8: i of type int has no subroutines",
+		},
+		{
+			name: "duplicate",
+			main: "class Foo {\n  function void f() { return; }\n  function void f() { return; }\n}",
+			want: "Main.jack:3:17: duplicate subroutine Foo.f\n" +
+				"Main.jack:1:7: duplicate class Foo",
+		},
+		{
+			name: "constants",
+			main: "class Main {\n  const int A = 1;\n  const int A = 2;\n  enum Dir { UP, UP }\n  function void main() {\n    do Output.printInt(Main.A + Dir.UP + Main.B + Bar.C);\n    return;\n  }\n}",
+			want: "Main.jack:3:13: duplicate constant Main.A\n" +
+				"Main.jack:4:18: duplicate constant Dir.UP\n" +
+				"Main.jack:6:47: undefined constant Main.B\n" +
+				"Main.jack:6:51: undefined class Bar",
+		},
+		{
+			name: "duplicate case",
+			main: "class Main {\n  function void main(char c) {\n    switch (c) {\n      case 'A': { return; }\n      case 65: { return; }\n      default: { return; }\n    }\n  }\n}",
+			want: "Main.jack:5:12: duplicate case 65",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog := NewProgram()
+			var trees []*InnerNode
+			for _, src := range []string{foo, tt.main} {
+				trees = append(trees, parseForTest(t, src, true))
+			}
+			var got []string
+			for _, tree := range trees {
+				if err := prog.AddClass(tree); err != nil {
+					got = append(got, err.Error())
+				}
+			}
+			for _, tree := range trees {
+				if err := prog.Check(tree); err != nil {
+					got = append(got, err.Error())
+				}
+			}
+			if strings.Join(got, "\n") != tt.want {
+				t.Errorf("Program.Check() = %v, want %v", strings.Join(got, "\n"), tt.want)
+			}
+		})
+	}
+}
+
+func TestSemanticErrorList_Sort(t *testing.T) {
+	l := SemanticErrorList{
+		semanticError(Pos{"Main.jack", 5, 3, 0}, "field"),
+		semanticError(Pos{"Main.jack", 2, 9, 0}, "param"),
+		semanticError(Pos{"A.jack", 7, 1, 0}, "other file"),
+		semanticError(Pos{"Main.jack", 2, 3, 0}, "first"),
+		semanticError(Pos{"Main.jack", 2, 9, 0}, "same position"),
+	}
+	l.Sort()
+	want := "A.jack:7:1: other file\n" +
+		"Main.jack:2:3: first\n" +
+		"Main.jack:2:9: param\n" +
+		"Main.jack:2:9: same position\n" +
+		"Main.jack:5:3: field"
+	if got := l.Error(); got != want {
+		t.Errorf("SemanticErrorList.Sort() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,148 @@
+package jack
+
+import "fmt"
+
+// compileCondition compiles the condition of if, while or for statements to jump to falseLabel if it is false.
+// In the short-circuit mode the right operand of & and | is evaluated only if the left one does not decide the result.
+func (c *Compiler) compileCondition(cond TreeNode, falseLabel string) ([]string, error) {
+	if !c.shortCircuit {
+		res, err := c.compile(cond)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, c.vmc.not(), c.vmc.ifGoTo(falseLabel))
+		return res, nil
+	}
+
+	skips := 0
+	var branch func(exps []TreeNode, label string, jumpIf bool) ([]string, error)
+	branch = func(exps []TreeNode, label string, jumpIf bool) ([]string, error) {
+		exps = unparen(exps)
+		i := rootOp(exps, c.precedence)
+		if i == 0 || (exps[i].Value() != "&" && exps[i].Value() != "|") {
+			res, err := c.traverseExpression(exps)
+			if err != nil {
+				return nil, err
+			}
+			// only true, -1, is true as in the normal condition jumping with not and if-goto
+			if jumpIf {
+				res = append(res, c.vmc.constant(-1)...)
+				res = append(res, c.vmc.eq())
+			} else {
+				res = append(res, c.vmc.not())
+			}
+			return append(res, c.vmc.ifGoTo(label)), nil
+		}
+
+		// a & b jumps if false when either does and a | b jumps if true when either does.
+		// Otherwise the right operand decides only if the left one does not skip it.
+		leftLabel, leftJumpIf := label, jumpIf
+		skip := ""
+		if (exps[i].Value() == "&") == jumpIf {
+			skip = fmt.Sprintf("%s.SKIP.%d", label, skips)
+			skips++
+			leftLabel, leftJumpIf = skip, !jumpIf
+		}
+		res, err := branch(exps[:i], leftLabel, leftJumpIf)
+		if err != nil {
+			return nil, err
+		}
+		right, err := branch(exps[i+1:], label, jumpIf)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, right...)
+		if skip != "" {
+			res = append(res, c.vmc.label(skip))
+		}
+		return res, nil
+	}
+	return branch(cond.ChildNodes(), falseLabel, false)
+}
+
+// unparen returns the expression inside parentheses if exps is a single parenthesized term.
+func unparen(exps []TreeNode) []TreeNode {
+	for len(exps) == 1 && exps[0].ChildNodes()[0].Type() == SymbolType && exps[0].ChildNodes()[0].Value() == "(" {
+		exps = exps[0].ChildNodes()[1].ChildNodes()
+	}
+	return exps
+}
+
+// CheckShortCircuit warns about the conditions of if, while and for statements whose behavior changes with -shortcircuit,
+// that is where the skipped operand of & or | calls a subroutine or reads an array, and where an operand may not be boolean,
+// as each operand is then tested for true instead of combined bitwise. All classes of the program must be added before.
+func (p *Program) CheckShortCircuit(class TreeNode) error {
+	var errs SemanticErrorList
+	tc := &typeChecker{prog: p} // only for the types, its problems are reported by TypeCheck
+	warnf := func(pos Pos, format string, a ...interface{}) {
+		e := semanticError(pos, format, a...)
+		e.Warning = true
+		errs = append(errs, e)
+	}
+	isLogical := func(exps []TreeNode) bool {
+		i := rootOp(exps, p.Precedence)
+		return i > 0 && (exps[i].Value() == "&" || exps[i].Value() == "|")
+	}
+	var check func(exps []TreeNode)
+	check = func(exps []TreeNode) {
+		exps = unparen(exps)
+		if !isLogical(exps) {
+			return
+		}
+		i := rootOp(exps, p.Precedence)
+		op := exps[i].Value()
+		if hasEffect(exps[i+1:]) {
+			decided := "false"
+			if op == "|" {
+				decided = "true"
+			}
+			warnf(exps[i].Pos(), "with -shortcircuit the right operand of %s, which calls a subroutine or reads an array, is skipped if the left one is %s", op, decided)
+		}
+		sides := []struct {
+			name string
+			exps []TreeNode
+		}{{"left", exps[:i]}, {"right", exps[i+1:]}}
+		for _, side := range sides {
+			operand := unparen(side.exps)
+			if isLogical(operand) {
+				continue // checked by its own operands
+			}
+			if tc.expressionType(operand) != "boolean" {
+				warnf(operand[0].Pos(), "with -shortcircuit the %s operand of %s, which may not be boolean, is tested for true instead of combined bitwise", side.name, op)
+			}
+		}
+		check(exps[:i])
+		check(exps[i+1:])
+	}
+	var walk func(node TreeNode)
+	walk = func(node TreeNode) {
+		switch node.Type() {
+		case ClassType:
+			for _, n := range node.ChildNodes() {
+				if n.Type() == ClassNameType {
+					tc.className = n.Value()
+				}
+			}
+		case IfStatementType, WhileStatementType, ForStatementType:
+			check(condition(node).ChildNodes())
+		}
+		for _, n := range node.ChildNodes() {
+			walk(n)
+		}
+	}
+	walk(class)
+	return errs.Err()
+}
+
+// hasEffect reports whether evaluating the nodes may call a subroutine or read an array.
+func hasEffect(nodes []TreeNode) bool {
+	for _, n := range nodes {
+		if n.Type() == SubroutineCallType || (n.Type() == TermType && len(n.ChildNodes()) == 4) {
+			return true
+		}
+		if hasEffect(n.ChildNodes()) {
+			return true
+		}
+	}
+	return false
+}
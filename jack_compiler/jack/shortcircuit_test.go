@@ -0,0 +1,59 @@
+package jack
+
+import "testing"
+
+func TestProgram_CheckShortCircuit(t *testing.T) {
+	tests := []struct {
+		name       string
+		src        string
+		precedence bool
+		want       string
+	}{
+		{
+			name: "no effect",
+			src:  "class Main {\n  function void f(int i, boolean b) {\n    if ((i > 0) & (i < 10) | ~b) { return; }\n    let i = (i > 0) & Main.g();\n    return;\n  }\n  function boolean g() { return true; }\n}",
+			want: "",
+		},
+		{
+			name: "skipped effects",
+			src:  "class Main {\n  function void f(Array a, int i) {\n    if ((i < 10) & (a[i] = 0)) { return; }\n    while ((i = 0) | Main.g() | (i > 2)) { let i = 0; }\n    return;\n  }\n  function boolean g() { return true; }\n}",
+			want: "Main.jack:3:18: warning: with -shortcircuit the right operand of &, which calls a subroutine or reads an array, is skipped if the left one is false\n" +
+				"Main.jack:4:20: warning: with -shortcircuit the right operand of |, which calls a subroutine or reads an array, is skipped if the left one is true",
+		},
+		{
+			name:       "precedence",
+			src:        "class Main {\n  function void f(int i) {\n    if (i = 0 | i > Main.g()) { return; }\n    return;\n  }\n  function int g() { return 0; }\n}",
+			precedence: true,
+			want:       "Main.jack:3:15: warning: with -shortcircuit the right operand of |, which calls a subroutine or reads an array, is skipped if the left one is true",
+		},
+		{
+			name: "non-boolean operands",
+			src:  "class Main {\n  function void f(int x, boolean b, Array a) {\n    if (x | (x < 0)) { return; }\n    while (b & a[0]) { return; }\n    return;\n  }\n}",
+			want: "Main.jack:3:9: warning: with -shortcircuit the left operand of |, which may not be boolean, is tested for true instead of combined bitwise\n" +
+				"Main.jack:4:14: warning: with -shortcircuit the right operand of &, which calls a subroutine or reads an array, is skipped if the left one is false\n" +
+				"Main.jack:4:16: warning: with -shortcircuit the right operand of &, which may not be boolean, is tested for true instead of combined bitwise",
+		},
+		{
+			name: "left to right",
+			src:  "class Main {\n  function void f(int i) {\n    if (i = 0 | i > Main.g()) { return; }\n    return;\n  }\n  function int g() { return 0; }\n}",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parseForTest(t, tt.src, false)
+			prog := NewProgram()
+			prog.Precedence = tt.precedence
+			if err := prog.AddClass(tree); err != nil {
+				t.Fatal(err)
+			}
+			got := ""
+			if err := prog.CheckShortCircuit(tree); err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("Program.CheckShortCircuit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
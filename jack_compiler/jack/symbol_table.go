@@ -1,4 +1,4 @@
-package main
+package jack
 
 import "fmt"
 
@@ -15,6 +15,7 @@ type SymbolTableEntry struct {
 	Typ   string
 	Kind  VarKind
 	Index int
+	Value int // value of a constant
 }
 
 type VarKind int
@@ -24,6 +25,7 @@ const (
 	Field
 	Argument
 	Var
+	Const // constants and enum members of the language extensions, inlined without storage
 )
 
 func (k VarKind) String() string {
@@ -36,6 +38,8 @@ func (k VarKind) String() string {
 		return "Argument"
 	case Var:
 		return "Var"
+	case Const:
+		return "Const"
 	}
 	return "Invalid"
 }
@@ -50,6 +54,8 @@ func NewVarKind(in string) (VarKind, error) {
 		return Argument, nil
 	case "var":
 		return Var, nil
+	case "const":
+		return Const, nil
 	}
 	return 0, fmt.Errorf("Invalid kind name of variable %v", in)
 }
@@ -96,6 +102,20 @@ func (s *SymbolTable) Define(name string, typ string, kind VarKind) error {
 	return nil
 }
 
+// DefineConst defines a constant in the class scope. It takes no index as its uses are replaced by the value.
+func (s *SymbolTable) DefineConst(name string, typ string, value int) error {
+	if _, ok := s.classScopeTable[name]; ok {
+		return fmt.Errorf("Duplicate symbol %v %v %v", name, typ, Const)
+	}
+	s.classScopeTable[name] = &SymbolTableEntry{
+		Name:  name,
+		Typ:   typ,
+		Kind:  Const,
+		Value: value,
+	}
+	return nil
+}
+
 func (s *SymbolTable) LookUp(name string) *SymbolTableEntry {
 	ste, ok := s.funcScopeTable[name]
 	if ok {
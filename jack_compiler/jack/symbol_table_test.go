@@ -1,4 +1,4 @@
-package main
+package jack
 
 import (
 	"reflect"
@@ -158,6 +158,26 @@ func TestSymbolTable_Define(t *testing.T) {
 	}
 }
 
+func TestSymbolTable_DefineConst(t *testing.T) {
+	s := NewSymbolTable()
+	if err := s.DefineConst("Main.WIDTH", "int", 512); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Define("x", "int", Static); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DefineConst("Main.WIDTH", "int", 256); err == nil {
+		t.Errorf("SymbolTable.DefineConst() error = nil, want duplicate error")
+	}
+	want := &SymbolTableEntry{Name: "Main.WIDTH", Typ: "int", Kind: Const, Value: 512}
+	if diff := cmp.Diff(s.LookUp("Main.WIDTH"), want); diff != "" {
+		t.Errorf("SymbolTable.LookUp() diff (-got +want)\n%s", diff)
+	}
+	if got := s.LookUp("x").Index; got != 0 {
+		t.Errorf("SymbolTable.LookUp() index = %d, want 0", got)
+	}
+}
+
 func TestSymbolTable_LookUp(t *testing.T) {
 	type fields struct {
 		classScopeTable ScopedTable
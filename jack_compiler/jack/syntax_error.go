@@ -0,0 +1,76 @@
+package jack
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SyntaxError is an error at a token which does not match the grammar.
+type SyntaxError struct {
+	Pos      Pos
+	Expected string
+	Found    string
+	// Msg describes the error instead of Expected and Found, such as an invalid literal found by the tokenizer.
+	Msg string
+	// remaining is the number of tokens left from the unexpected token to the end of file.
+	// This tells whether a production failed at its first token (i.e. it does not start there) or after consuming some tokens.
+	remaining int
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Msg != "" {
+		return fmt.Sprintf("%v: %s", e.Pos, e.Msg)
+	}
+	if e.Expected == "" {
+		return fmt.Sprintf("%v: unexpected %s", e.Pos, e.Found)
+	}
+	return fmt.Sprintf("%v: expected %s, found %s", e.Pos, e.Expected, e.Found)
+}
+
+// Snippet shows where the error is in src.
+func (e *SyntaxError) Snippet(src []byte) string {
+	return snippet(src, e.Pos)
+}
+
+// SyntaxErrorList is a list of syntax errors in a file.
+type SyntaxErrorList []*SyntaxError
+
+func (l SyntaxErrorList) Error() string {
+	var msgs []string
+	for _, e := range l {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func syntaxError(found TreeNode, remaining int, expected string) *SyntaxError {
+	return &SyntaxError{
+		Pos:       found.Pos(),
+		Expected:  expected,
+		Found:     fmt.Sprintf("'%s'", found.Value()),
+		remaining: remaining,
+	}
+}
+
+// tokenError is a syntax error of the tokenizer, which has no expected token.
+func tokenError(pos Pos, format string, a ...interface{}) *SyntaxError {
+	return &SyntaxError{
+		Pos: pos,
+		Msg: fmt.Sprintf(format, a...),
+	}
+}
+
+func eofError() *SyntaxError {
+	return &SyntaxError{
+		Found:     "end of file",
+		remaining: 0,
+	}
+}
+
+// notStarted reports whether err occurred at the first token of tokens, which means that the production does not start there
+// and the caller may try another one. Otherwise the production has started and err is a real syntax error.
+func notStarted(err error, tokens TokenList) bool {
+	var se *SyntaxError
+	return errors.As(err, &se) && se.remaining == len(tokens)
+}
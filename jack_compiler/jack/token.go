@@ -0,0 +1,349 @@
+package jack
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Tokens []Token
+
+func NewTokens(tokens []Token) Tokens {
+	return Tokens(tokens)
+}
+
+func (t *Tokens) Xml() string {
+	res := []string{`<tokens>`}
+	for _, tkn := range *t {
+		res = append(res, tkn.Xml())
+	}
+	res = append(res, `</tokens>`)
+	return strings.Join(res, "\n")
+}
+
+type Token interface {
+	Type() NodeType
+	Xml() string
+	String() string
+	Name() string
+	Pos() Pos
+	End() Pos
+}
+
+// PosToken is a token with its position in the source file.
+// E is the position just after the last character of the token.
+type PosToken struct {
+	Token
+	P Pos
+	E Pos
+}
+
+func NewPosToken(t Token, p Pos, e Pos) PosToken {
+	return PosToken{Token: t, P: p, E: e}
+}
+
+func (t PosToken) Pos() Pos {
+	return t.P
+}
+
+func (t PosToken) End() Pos {
+	return t.E
+}
+
+type KeywordToken string
+
+func NewKeywordToken(in string) (KeywordToken, bool) {
+	switch in {
+	case "class", "constructor", "function", "method", "field", "static", "var", "int", "char", "boolean",
+		"void", "true", "false", "null", "this", "let", "do", "if", "else", "while", "return":
+		return KeywordToken(in), true
+	}
+	return "", false
+}
+
+// newExtKeywordToken returns the keywords added by the language extensions.
+func newExtKeywordToken(in string) (KeywordToken, bool) {
+	switch in {
+	case "for", "break", "continue", "switch", "case", "default", "const", "enum":
+		return KeywordToken(in), true
+	}
+	return "", false
+}
+
+func (t KeywordToken) Type() NodeType {
+	return KeywordType
+}
+
+func (t KeywordToken) String() string {
+	return string(t)
+}
+
+func (t KeywordToken) Int() int {
+	return 0
+}
+
+func (t KeywordToken) Xml() string {
+	return fmt.Sprintf("<%s>%s</%s>", t.Name(), escapeXml(t.String()), t.Name())
+}
+
+func (t KeywordToken) Name() string {
+	return "keyword"
+}
+
+func (t KeywordToken) Pos() Pos {
+	return Pos{}
+}
+
+func (t KeywordToken) End() Pos {
+	return Pos{}
+}
+
+type SymbolToken string
+
+func NewSymbolToken(in string) (SymbolToken, bool) {
+	switch in {
+	case "{", "}", "(", ")", "[", "]", ".", ",", ";", "+", "-", "*", "/", "&", "|", "<", ">", "=", "~":
+		return SymbolToken(in), true
+	}
+	return "", false
+}
+
+// newExtSymbolToken returns the symbols added by the language extensions.
+func newExtSymbolToken(in string) (SymbolToken, bool) {
+	if in == ":" {
+		return SymbolToken(in), true
+	}
+	return "", false
+}
+
+func (t SymbolToken) Type() NodeType {
+	return SymbolType
+}
+
+func (t SymbolToken) String() string {
+	return string(t)
+}
+
+func (t SymbolToken) Int() int {
+	return 0
+}
+
+func (t SymbolToken) Xml() string {
+	return fmt.Sprintf("<%s>%s</%s>", t.Name(), escapeXml(t.String()), t.Name())
+}
+
+func (t SymbolToken) Name() string {
+	return "symbol"
+}
+
+func (t SymbolToken) Pos() Pos {
+	return Pos{}
+}
+
+func (t SymbolToken) End() Pos {
+	return Pos{}
+}
+
+type IntConstToken int
+
+// NewIntConstToken makes an integer constant in 0..32767, or 32768 which is only valid as the operand of unary minus.
+// The parser checks the latter.
+func NewIntConstToken(in int) (IntConstToken, bool) {
+	if 0 <= in && in <= maxIntConst+1 {
+		return IntConstToken(in), true
+	}
+	return 0, false
+}
+
+const maxIntConst = 32767
+
+var intLiteral = regexp.MustCompile(`^([0-9]+|0[xX][0-9a-fA-F]+|0[bB][01]+)$`)
+
+// parseIntLiteral parses the decimal, hex (0x7FFF) or binary (0b1010) literal.
+// It returns false if in is not an integer literal, and an error if the value is too large.
+func parseIntLiteral(in string) (int, bool, error) {
+	if !intLiteral.MatchString(in) {
+		return 0, false, nil
+	}
+	digits, base := in, 10
+	switch {
+	case strings.HasPrefix(in, "0x"), strings.HasPrefix(in, "0X"):
+		digits, base = in[2:], 16
+	case strings.HasPrefix(in, "0b"), strings.HasPrefix(in, "0B"):
+		digits, base = in[2:], 2
+	}
+	i, err := strconv.ParseInt(digits, base, 32)
+	if err != nil || i > maxIntConst+1 {
+		return 0, true, fmt.Errorf("integer constant %s is out of range 0..%d", in, maxIntConst)
+	}
+	return int(i), true, nil
+}
+
+func (t IntConstToken) Type() NodeType {
+	return IntConstType
+}
+
+func (t IntConstToken) String() string {
+	return strconv.Itoa(int(t))
+}
+
+func (t IntConstToken) Int() int {
+	return int(t)
+}
+
+func (t IntConstToken) Xml() string {
+	return fmt.Sprintf("<%s>%d</%s>", t.Name(), t.Int(), t.Name())
+}
+
+func (t IntConstToken) Name() string {
+	return "integerConstant"
+}
+
+func (t IntConstToken) Pos() Pos {
+	return Pos{}
+}
+
+func (t IntConstToken) End() Pos {
+	return Pos{}
+}
+
+type StrConstToken string
+
+// NewStrConstToken makes a string constant of the source text between the quotes, which may have escape sequences.
+func NewStrConstToken(in string) (StrConstToken, bool) {
+	if _, _, err := hackChars(in); err != nil {
+		return "", false
+	}
+	return StrConstToken(in), true
+}
+
+// hackChars decodes the string constant to the codes of the Hack character set.
+// The escape sequences are \", \', \\, \n (newline, 128), \b (backspace, 129), \t (a space, as the Hack font has no tab)
+// and \xNN of a code in hex. Other backslashes are literal. On error it also returns the index of the rune where the error is.
+func hackChars(in string) ([]int, int, error) {
+	var res []int
+	runes := []rune(in)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r != '\\' {
+			if r < 32 || r > 126 {
+				return nil, i, fmt.Errorf("character %q is not in the Hack character set", r)
+			}
+			res = append(res, int(r))
+			continue
+		}
+
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		switch next {
+		case '"', '\'', '\\':
+			res = append(res, int(runes[i+1]))
+		case 'n':
+			res = append(res, 128)
+		case 'b':
+			res = append(res, 129)
+		case 't':
+			res = append(res, ' ')
+		case 'x':
+			if i+4 > len(runes) {
+				return nil, i, fmt.Errorf("escape sequence \\x needs two hex digits")
+			}
+			c, err := strconv.ParseUint(string(runes[i+2:i+4]), 16, 8)
+			if err != nil {
+				return nil, i, fmt.Errorf("escape sequence \\x needs two hex digits")
+			}
+			if !isHackChar(int(c)) {
+				return nil, i, fmt.Errorf("character code %d is not in the Hack character set", c)
+			}
+			res = append(res, int(c))
+			i += 2
+		default:
+			// a backslash not starting an escape sequence is itself, as in "[\]" of the OS test programs
+			res = append(res, '\\')
+			continue
+		}
+		i++
+	}
+	return res, 0, nil
+}
+
+// isHackChar reports whether the code is a printable character or a key of the Hack character set.
+func isHackChar(c int) bool {
+	return 32 <= c && c <= 126 || 128 <= c && c <= 152
+}
+
+func (t StrConstToken) Type() NodeType {
+	return StrConstType
+}
+
+func (t StrConstToken) String() string {
+	return string(t)
+}
+
+func (t StrConstToken) Int() int {
+	return 0
+}
+
+func (t StrConstToken) Xml() string {
+	return fmt.Sprintf("<%s>%s</%s>", t.Name(), escapeXml(t.String()), t.Name())
+}
+
+func (t StrConstToken) Name() string {
+	return "stringConstant"
+}
+
+func (t StrConstToken) Pos() Pos {
+	return Pos{}
+}
+
+func (t StrConstToken) End() Pos {
+	return Pos{}
+}
+
+type IdentifierToken string
+
+func NewIdentifierToken(in string) (IdentifierToken, bool) {
+	valid := regexp.MustCompile(`^[a-zA-Z_]([a-zA-Z0-9_]+)?$`)
+	if valid.MatchString(in) {
+		return IdentifierToken(in), true
+	}
+	return "", false
+}
+
+func (t IdentifierToken) Type() NodeType {
+	return IdentifierType
+}
+
+func (t IdentifierToken) String() string {
+	return string(t)
+}
+
+func (t IdentifierToken) Int() int {
+	return 0
+}
+
+func (t IdentifierToken) Xml() string {
+	return fmt.Sprintf("<%s>%s</%s>", t.Name(), escapeXml(t.String()), t.Name())
+}
+
+func (t IdentifierToken) Name() string {
+	return "identifier"
+}
+
+func (t IdentifierToken) Pos() Pos {
+	return Pos{}
+}
+
+func (t IdentifierToken) End() Pos {
+	return Pos{}
+}
+
+func escapeXml(in string) string {
+	a := strings.ReplaceAll(in, "&", "&amp;")
+	b := strings.ReplaceAll(a, "<", "&lt;")
+	c := strings.ReplaceAll(b, ">", "&gt;")
+	return c
+}
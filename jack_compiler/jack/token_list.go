@@ -1,6 +1,4 @@
-package main
-
-import "fmt"
+package jack
 
 // TokenList is list of tokens to parse, received from tokenizer.
 type TokenList []Token
@@ -11,7 +9,7 @@ func NewTokenList(t []Token) TokenList {
 
 func (l TokenList) PopAt(at int) (TreeNode, TokenList, error) {
 	if len(l) <= at {
-		return nil, nil, fmt.Errorf("Invalid index %d", at)
+		return nil, nil, eofError()
 	}
 	tkn := l[at]
 	rest := NewTokenList(l[at+1 : len(l)])
@@ -24,8 +22,16 @@ func (l TokenList) PopNext() (TreeNode, TokenList, error) {
 
 func (l TokenList) LookAt(at int) (TreeNode, error) {
 	if len(l) <= at {
-		return nil, fmt.Errorf("Invalid index %d", at)
+		return nil, eofError()
 	}
 	tkn := l[at]
 	return AdaptTokenToNode(tkn), nil
 }
+
+// Pos returns the position of the next token.
+func (l TokenList) Pos() Pos {
+	if len(l) == 0 {
+		return Pos{}
+	}
+	return l[0].Pos()
+}
@@ -0,0 +1,149 @@
+package jack
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewIdentifierToken(t *testing.T) {
+	type args struct {
+		in string
+	}
+	tests := []struct {
+		name  string
+		args  args
+		want  IdentifierToken
+		want1 bool
+	}{
+		{
+			name:  "normal",
+			args:  args{in: "var_1"},
+			want:  "var_1",
+			want1: true,
+		},
+		{
+			name:  "single char",
+			args:  args{in: "i"},
+			want:  "i",
+			want1: true,
+		},
+		{
+			name:  "first letter should not a digit",
+			args:  args{in: "1var"},
+			want:  "",
+			want1: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := NewIdentifierToken(tt.args.in)
+			if got != tt.want {
+				t.Errorf("NewIdentifierToken() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("NewIdentifierToken() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestHackChars(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []int
+		wantAt  int
+		wantErr bool
+	}{
+		{
+			name: "printable",
+			in:   "a Z~",
+			want: []int{97, 32, 90, 126},
+		},
+		{
+			name: "escapes",
+			in:   `\"\\\n\b\t\x41\x80`,
+			want: []int{34, 92, 128, 129, 32, 65, 128},
+		},
+		{
+			name: "empty",
+			in:   "",
+		},
+		{
+			name: "literal backslash",
+			in:   `[\]\`,
+			want: []int{91, 92, 93, 92},
+		},
+		{
+			name:    "non ascii",
+			in:      "abé",
+			wantAt:  2,
+			wantErr: true,
+		},
+		{
+			name:    "control character",
+			in:      "a\tb",
+			wantAt:  1,
+			wantErr: true,
+		},
+		{
+			name:    "hex not in character set",
+			in:      `ab\x7f`,
+			wantAt:  2,
+			wantErr: true,
+		},
+		{
+			name:    "short hex",
+			in:      `\x4`,
+			wantAt:  0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, at, err := hackChars(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("hackChars() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if at != tt.wantAt {
+				t.Errorf("hackChars() at = %d, want %d", at, tt.wantAt)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("hackChars() diff (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseIntLiteral(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantOk  bool
+		wantErr bool
+	}{
+		{in: "0", want: 0, wantOk: true},
+		{in: "007", want: 7, wantOk: true},
+		{in: "32767", want: 32767, wantOk: true},
+		{in: "32768", want: 32768, wantOk: true},
+		{in: "32769", wantOk: true, wantErr: true},
+		{in: "99999999999999999999", wantOk: true, wantErr: true},
+		{in: "0x7fFF", want: 32767, wantOk: true},
+		{in: "0B1010", want: 10, wantOk: true},
+		{in: "0b102", wantOk: false},
+		{in: "0x", wantOk: false},
+		{in: "12ab", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok, err := parseIntLiteral(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIntLiteral() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("parseIntLiteral() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
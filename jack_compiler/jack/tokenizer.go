@@ -0,0 +1,464 @@
+package jack
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenizeState int
+
+const (
+	ordinal            tokenizeState = iota + 1
+	multiCommentOpened               // /* */
+	stringOpened                     // "blah"
+)
+
+type Tokenizer struct {
+	reader     io.Reader
+	fileName   string
+	tokens     []Token
+	state      tokenizeState
+	buf        string
+	bufPos     Pos
+	line       int
+	lineOffset int
+	consumed   int
+	runeOffset []int
+	comments   []Comment
+	comment    Comment // multi line comment being read
+	commentAt  int     // index of the rune where comment starts in the current line
+	extensions bool
+}
+
+// Comment is a comment in the source file including its delimiters. E is the position just after the comment.
+type Comment struct {
+	Text string
+	P    Pos
+	E    Pos
+}
+
+func NewTokenizer(r io.Reader, fileName string) *Tokenizer {
+	return &Tokenizer{
+		reader:   r,
+		fileName: fileName,
+		state:    ordinal,
+	}
+}
+
+// SetExtensions makes the tokenizer read the keywords and symbols of the language extensions.
+// Otherwise the keywords are identifiers.
+func (t *Tokenizer) SetExtensions(on bool) {
+	t.extensions = on
+}
+
+// Tokenize reads the tokens of the source. It stops at the first invalid token and returns it as a SyntaxErrorList
+// like Parser.Parse, so that the errors of both are reported in the same way.
+func (t *Tokenizer) Tokenize() (Tokens, error) {
+	var res []Token
+
+	scanner := bufio.NewScanner(t.reader)
+	scanner.Split(t.scanLines)
+	for scanner.Scan() {
+		l := scanner.Text()
+		t.line++
+		tokens, err := t.parseLine(l)
+		if err != nil {
+			var se *SyntaxError
+			if errors.As(err, &se) {
+				return nil, SyntaxErrorList{se}
+			}
+			return nil, err
+		}
+		if tokens == nil {
+			continue
+		}
+		res = append(res, tokens...)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	t.tokens = res
+	return NewTokens(res), nil
+}
+
+// Comments returns the comments in the source file, which are not tokens, in order of appearance.
+func (t *Tokenizer) Comments() []Comment {
+	return t.comments
+}
+
+// scanLines is bufio.ScanLines which also records the byte offset of the line.
+func (t *Tokenizer) scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+	if token != nil {
+		t.lineOffset = t.consumed
+	}
+	t.consumed += advance
+	return advance, token, err
+}
+
+func (t *Tokenizer) parseLine(l string) ([]Token, error) {
+	var res []Token
+	runes := []rune(l)
+
+	// one more entry for the end of the line
+	t.runeOffset = make([]int, len(runes)+1)
+	offset := 0
+	for i, r := range runes {
+		t.runeOffset[i] = offset
+		offset += utf8.RuneLen(r)
+	}
+	t.runeOffset[len(runes)] = offset
+	t.commentAt = 0
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if t.state == ordinal {
+			if t.singleComment(runes, i) {
+				tkn, err := t.flushBuf(t.pos(i))
+				if err != nil {
+					return nil, err
+				}
+				if tkn != nil {
+					res = append(res, tkn)
+				}
+				t.comments = append(t.comments, Comment{
+					Text: string(runes[i:]),
+					P:    t.pos(i),
+					E:    t.pos(len(runes)),
+				})
+
+				break
+			}
+
+			if t.multiCommentOpen(runes, i) {
+				tkn, err := t.flushBuf(t.pos(i))
+				if err != nil {
+					return nil, err
+				}
+				if tkn != nil {
+					res = append(res, tkn)
+				}
+
+				if err := t.transit(multiCommentOpened); err != nil {
+					return nil, tokenError(t.pos(i), "Invalid multi comment opening. %v", err)
+				}
+				t.comment = Comment{P: t.pos(i)}
+				t.commentAt = i
+				i++
+				continue
+			}
+
+			if t.stringQuote(r) {
+				tkn, err := t.flushBuf(t.pos(i))
+				if err != nil {
+					return nil, err
+				}
+				if tkn != nil {
+					res = append(res, tkn)
+				}
+
+				if err := t.transit(stringOpened); err != nil {
+					return nil, tokenError(t.pos(i), "Invalid string opening. %v", err)
+				}
+				t.bufPos = t.pos(i)
+				continue
+			}
+
+			if r == '\'' {
+				tkn, err := t.flushBuf(t.pos(i))
+				if err != nil {
+					return nil, err
+				}
+				if tkn != nil {
+					res = append(res, tkn)
+				}
+
+				tkn, end, err := t.charLiteral(runes, i)
+				if err != nil {
+					return nil, err
+				}
+				res = append(res, tkn)
+				i = end - 1
+				continue
+			}
+
+			if t.delim(r) {
+				tkn, err := t.flushBuf(t.pos(i))
+				if err != nil {
+					return nil, err
+				}
+				if tkn != nil {
+					res = append(res, tkn)
+				}
+
+				sym, ok := t.symbol(r)
+				if ok {
+					res = append(res, NewPosToken(sym, t.pos(i), t.pos(i+1)))
+				}
+
+				continue
+			}
+
+			if t.buf == "" {
+				t.bufPos = t.pos(i)
+			}
+			t.appendBuf(r)
+		}
+
+		if t.state == multiCommentOpened {
+			if t.multiCommentClose(runes, i) {
+				if err := t.transit(ordinal); err != nil {
+					return nil, tokenError(t.pos(i), "Invalid multi comment closing. %v", err)
+				}
+				t.comment.Text += string(runes[t.commentAt : i+2])
+				t.comment.E = t.pos(i + 2)
+				t.comments = append(t.comments, t.comment)
+				i++
+				continue
+			}
+			continue
+		}
+
+		if t.state == stringOpened {
+			if r == '\\' && i+1 < len(runes) {
+				// the escaped character, which may be a quote, does not close the string
+				t.appendBuf(r)
+				t.appendBuf(runes[i+1])
+				i++
+				continue
+			}
+
+			if t.stringQuote(r) {
+				// the buffer holds every rune between the quotes, so the string starts that many runes before the closing one
+				if _, at, err := hackChars(t.buf); err != nil {
+					return nil, tokenError(t.pos(i-len([]rune(t.buf))+at), "%v", err)
+				}
+				tkn, err := t.flushBuf(t.pos(i + 1))
+				if err != nil {
+					return nil, err
+				}
+				if tkn != nil {
+					res = append(res, tkn)
+				}
+
+				if err := t.transit(ordinal); err != nil {
+					return nil, tokenError(t.pos(i), "Invalid string closing. %v", err)
+				}
+				continue
+			}
+
+			t.appendBuf(r)
+		}
+	}
+
+	if t.state == multiCommentOpened {
+		t.comment.Text += string(runes[t.commentAt:]) + "\n"
+	}
+
+	// a string constant can not contain a line break
+	if t.state == stringOpened {
+		return nil, tokenError(t.bufPos, "unterminated string constant")
+	}
+
+	// a line break also delimits tokens
+	if t.state == ordinal {
+		tkn, err := t.flushBuf(t.pos(len(runes)))
+		if err != nil {
+			return nil, err
+		}
+		if tkn != nil {
+			res = append(res, tkn)
+		}
+	}
+
+	return res, nil
+}
+
+// pos returns the position of the i-th rune of the current line. i may be the length of the line.
+func (t *Tokenizer) pos(i int) Pos {
+	return Pos{
+		File:   t.fileName,
+		Line:   t.line,
+		Column: i + 1,
+		Offset: t.lineOffset + t.runeOffset[i],
+	}
+}
+
+// flushBuf converts the buffer to a token positioned from the start of the buffer to end, and clears the buffer.
+func (t *Tokenizer) flushBuf(end Pos) (Token, error) {
+	tkn, err := t.bufToToken()
+	if err != nil {
+		return nil, tokenError(t.bufPos, "%v", err)
+	}
+	t.clearBuf()
+	if tkn == nil {
+		return nil, nil
+	}
+	return NewPosToken(tkn, t.bufPos, end), nil
+}
+
+func (t *Tokenizer) appendBuf(c rune) {
+	t.buf += string(c)
+}
+
+func (t *Tokenizer) clearBuf() {
+	t.buf = ""
+}
+
+func (t *Tokenizer) bufToToken() (Token, error) {
+	b := t.buf
+
+	if b == "" && t.state != stringOpened {
+		return nil, nil
+	}
+
+	kw, ok := NewKeywordToken(b)
+	if ok {
+		return kw, nil
+	}
+	if t.state == ordinal {
+		if kw, ok := newExtKeywordToken(b); ok && t.extensions {
+			return kw, nil
+		}
+		id, ok := NewIdentifierToken(b)
+		if ok {
+			return id, nil
+		}
+	}
+
+	if t.state == stringOpened {
+		st, ok := NewStrConstToken(b)
+		if ok {
+			return st, nil
+		}
+	}
+
+	i, ok, err := parseIntLiteral(b)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		it, _ := NewIntConstToken(i) // in range
+		return it, nil
+	}
+
+	return nil, fmt.Errorf("Invalid token %s", t.buf)
+}
+
+// charLiteral reads the character literal such as 'A' or '\n' starting at the idx-th rune,
+// which is an integer constant of the code. It also returns the index of the rune after the literal.
+func (t *Tokenizer) charLiteral(runes []rune, idx int) (Token, int, error) {
+	end := idx + 1
+	for end < len(runes) && runes[end] != '\'' {
+		if runes[end] == '\\' {
+			end++
+		}
+		end++
+	}
+	if end >= len(runes) {
+		return nil, 0, tokenError(t.pos(idx), "unterminated character literal")
+	}
+
+	chars, at, err := hackChars(string(runes[idx+1 : end]))
+	if err != nil {
+		return nil, 0, tokenError(t.pos(idx+1+at), "%v", err)
+	}
+	if len(chars) != 1 {
+		return nil, 0, tokenError(t.pos(idx), "character literal must have one character")
+	}
+	return NewPosToken(IntConstToken(chars[0]), t.pos(idx), t.pos(end+1)), end + 1, nil
+}
+
+func (t *Tokenizer) singleComment(runes []rune, idx int) bool {
+	if idx+1 >= len(runes) {
+		return false
+	}
+	if runes[idx] != '/' || runes[idx+1] != '/' {
+		return false
+	}
+	return true
+}
+
+func (t *Tokenizer) multiCommentOpen(runes []rune, idx int) bool {
+	if idx+1 >= len(runes) {
+		return false
+	}
+	if runes[idx] != '/' || runes[idx+1] != '*' {
+		return false
+	}
+	return true
+}
+
+func (t *Tokenizer) multiCommentClose(runes []rune, idx int) bool {
+	if idx+1 >= len(runes) {
+		return false
+	}
+	if runes[idx] != '*' || runes[idx+1] != '/' {
+		return false
+	}
+	return true
+}
+
+func (t *Tokenizer) stringQuote(r rune) bool {
+	if r == '"' {
+		return true
+	}
+	return false
+}
+
+func (t *Tokenizer) delim(c rune) bool {
+	if t.state != ordinal {
+		return false
+	}
+	if unicode.IsSpace(c) {
+		return true
+	}
+	if _, ok := t.symbol(c); ok {
+		return true
+	}
+	return false
+}
+
+func (t *Tokenizer) symbol(c rune) (SymbolToken, bool) {
+	if sym, ok := newExtSymbolToken(string(c)); ok && t.extensions {
+		return sym, true
+	}
+	return NewSymbolToken(string(c))
+}
+
+func (t *Tokenizer) transit(next tokenizeState) error {
+	if !t.canTransit(next) {
+		return fmt.Errorf("Invalid status transition from %v to %v", t.state, next)
+	}
+	t.state = next
+	return nil
+}
+
+func (t *Tokenizer) canTransit(next tokenizeState) bool {
+	cur := t.state
+	switch cur {
+	case ordinal:
+		switch next {
+		case multiCommentOpened, stringOpened:
+			return true
+		}
+	case multiCommentOpened:
+		switch next {
+		case ordinal:
+			return true
+		}
+	case stringOpened:
+		switch next {
+		case ordinal:
+			return true
+		}
+	}
+	return false
+}
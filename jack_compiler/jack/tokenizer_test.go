@@ -0,0 +1,581 @@
+package jack
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// ignorePos compares tokens regardless of their positions.
+var ignorePos = cmp.Transformer("IgnorePos", func(t Token) string {
+	return t.Xml()
+})
+
+func TestTokenizer_parseLine(t *testing.T) {
+	type fields struct {
+		state tokenizeState
+	}
+	type args struct {
+		l string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []Token
+		wantErr bool
+	}{
+		{
+			name:   "class declaration",
+			fields: fields{state: ordinal},
+			args:   args{l: `class Main {`},
+			want: []Token{
+				KeywordToken("class"),
+				IdentifierToken("Main"),
+				SymbolToken("{"),
+			},
+			wantErr: false,
+		},
+		{
+			name:   "function declaration",
+			fields: fields{state: ordinal},
+			args:   args{l: `function void main() {`},
+			want: []Token{
+				KeywordToken("function"),
+				KeywordToken("void"),
+				IdentifierToken("main"),
+				SymbolToken("("),
+				SymbolToken(")"),
+				SymbolToken("{"),
+			},
+			wantErr: false,
+		},
+		{
+			name:   "statements",
+			fields: fields{state: ordinal},
+			args:   args{l: `let a = Array.new(length);`},
+			want: []Token{
+				KeywordToken("let"),
+				IdentifierToken("a"),
+				SymbolToken("="),
+				IdentifierToken("Array"),
+				SymbolToken("."),
+				IdentifierToken("new"),
+				SymbolToken("("),
+				IdentifierToken("length"),
+				SymbolToken(")"),
+				SymbolToken(";"),
+			},
+			wantErr: false,
+		},
+		{
+			name:   "should ignore spaces",
+			fields: fields{state: ordinal},
+			args:   args{l: `   let     a=123   ; `},
+			want: []Token{
+				KeywordToken("let"),
+				IdentifierToken("a"),
+				SymbolToken("="),
+				IntConstToken(123),
+				SymbolToken(";"),
+			},
+			wantErr: false,
+		},
+		{
+			name:   "divisor",
+			fields: fields{state: ordinal},
+			args:   args{l: `let div = 10/5;`},
+			want: []Token{
+				KeywordToken("let"),
+				IdentifierToken("div"),
+				SymbolToken("="),
+				IntConstToken(10),
+				SymbolToken("/"),
+				IntConstToken(5),
+				SymbolToken(";"),
+			},
+			wantErr: false,
+		},
+		{
+			name:   "single line comment",
+			fields: fields{state: ordinal},
+			args:   args{l: `let a = 123; // let b = 100;`},
+			want: []Token{
+				KeywordToken("let"),
+				IdentifierToken("a"),
+				SymbolToken("="),
+				IntConstToken(123),
+				SymbolToken(";"),
+			},
+			wantErr: false,
+		},
+		{
+			name:    "single line comment only",
+			fields:  fields{state: ordinal},
+			args:    args{l: `   // let b = 100;`},
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:   "single line comment just after identifier",
+			fields: fields{state: ordinal},
+			args:   args{l: `let b = sum// new line`},
+			want: []Token{
+				KeywordToken("let"),
+				IdentifierToken("b"),
+				SymbolToken("="),
+				IdentifierToken("sum"),
+			},
+			wantErr: false,
+		},
+		{
+			name:   "multi line comment opened",
+			fields: fields{state: ordinal},
+			args:   args{l: `let a = 123; /* let b = 100;`},
+			want: []Token{
+				KeywordToken("let"),
+				IdentifierToken("a"),
+				SymbolToken("="),
+				IntConstToken(123),
+				SymbolToken(";"),
+			},
+			wantErr: false,
+		},
+		{
+			name:   "multi line comment closed",
+			fields: fields{state: multiCommentOpened},
+			args:   args{l: `let a = 123; */ let b = 100;`},
+			want: []Token{
+				KeywordToken("let"),
+				IdentifierToken("b"),
+				SymbolToken("="),
+				IntConstToken(100),
+				SymbolToken(";"),
+			},
+			wantErr: false,
+		},
+		{
+			name:    "multi line comment only",
+			fields:  fields{state: ordinal},
+			args:    args{l: `/** some document`},
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "opening multi line comment only",
+			fields:  fields{state: multiCommentOpened},
+			args:    args{l: `some document */`},
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:   "string constant",
+			fields: fields{state: ordinal},
+			args:   args{l: `let length = Keyboard.readInt("HOW MANY NUMBERS? ");`},
+			want: []Token{
+				KeywordToken("let"),
+				IdentifierToken("length"),
+				SymbolToken("="),
+				IdentifierToken("Keyboard"),
+				SymbolToken("."),
+				IdentifierToken("readInt"),
+				SymbolToken("("),
+				StrConstToken("HOW MANY NUMBERS? "),
+				SymbolToken(")"),
+				SymbolToken(";"),
+			},
+			wantErr: false,
+		},
+		{
+			name:   "digit only string",
+			fields: fields{state: ordinal},
+			args:   args{l: `let s = "-123";`},
+			want: []Token{
+				KeywordToken("let"),
+				IdentifierToken("s"),
+				SymbolToken("="),
+				StrConstToken("-123"),
+				SymbolToken(";"),
+			},
+			wantErr: false,
+		},
+		{
+			name:   "empty string",
+			fields: fields{state: ordinal},
+			args:   args{l: `let s = "";`},
+			want: []Token{
+				KeywordToken("let"),
+				IdentifierToken("s"),
+				SymbolToken("="),
+				StrConstToken(""),
+				SymbolToken(";"),
+			},
+			wantErr: false,
+		},
+		{
+			name:   "escaped quote and backslash",
+			fields: fields{state: ordinal},
+			args:   args{l: `let s = "say \"hi\" \\";`},
+			want: []Token{
+				KeywordToken("let"),
+				IdentifierToken("s"),
+				SymbolToken("="),
+				StrConstToken(`say \"hi\" \\`),
+				SymbolToken(";"),
+			},
+			wantErr: false,
+		},
+		{
+			name:    "character not in Hack character set",
+			fields:  fields{state: ordinal},
+			args:    args{l: `let s = "é";`},
+			wantErr: true,
+		},
+		{
+			name:   "literal backslash",
+			fields: fields{state: ordinal},
+			args:   args{l: `let s = "[\]";`},
+			want: []Token{
+				KeywordToken("let"),
+				IdentifierToken("s"),
+				SymbolToken("="),
+				StrConstToken(`[\]`),
+				SymbolToken(";"),
+			},
+			wantErr: false,
+		},
+		{
+			name:   "hex, binary and character literals",
+			fields: fields{state: ordinal},
+			args:   args{l: `let a = 0x7FFF+0b1010+'A'+' '+'\n'+'\'';`},
+			want: []Token{
+				KeywordToken("let"),
+				IdentifierToken("a"),
+				SymbolToken("="),
+				IntConstToken(32767),
+				SymbolToken("+"),
+				IntConstToken(10),
+				SymbolToken("+"),
+				IntConstToken(65),
+				SymbolToken("+"),
+				IntConstToken(32),
+				SymbolToken("+"),
+				IntConstToken(128),
+				SymbolToken("+"),
+				IntConstToken(39),
+				SymbolToken(";"),
+			},
+			wantErr: false,
+		},
+		{
+			name:    "int out of range",
+			fields:  fields{state: ordinal},
+			args:    args{l: `let a = 40000;`},
+			wantErr: true,
+		},
+		{
+			name:    "hex out of range",
+			fields:  fields{state: ordinal},
+			args:    args{l: `let a = 0x10000;`},
+			wantErr: true,
+		},
+		{
+			name:    "character literal of two characters",
+			fields:  fields{state: ordinal},
+			args:    args{l: `let a = 'ab';`},
+			wantErr: true,
+		},
+		{
+			name:    "unterminated character literal",
+			fields:  fields{state: ordinal},
+			args:    args{l: `let a = 'a;`},
+			wantErr: true,
+		},
+		{
+			name:    "hex escape not in Hack character set",
+			fields:  fields{state: ordinal},
+			args:    args{l: `let s = "a\x09";`},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenizer := &Tokenizer{
+				state: tt.fields.state,
+			}
+			got, err := tokenizer.parseLine(tt.args.l)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Tokenizer.parseLine() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := cmp.Diff(got, tt.want, ignorePos); diff != "" {
+				t.Errorf("Tokenizer.parseLine() diff (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTokenizer_delim(t *testing.T) {
+	type fields struct {
+		state tokenizeState
+	}
+	type args struct {
+		c rune
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   bool
+	}{
+		{
+			name:   "not delim",
+			fields: fields{state: ordinal},
+			args:   args{c: 'a'},
+			want:   false,
+		},
+		{
+			name:   "space is delim",
+			fields: fields{state: ordinal},
+			args:   args{c: ' '},
+			want:   true,
+		},
+		{
+			name:   "symbol is delim",
+			fields: fields{state: ordinal},
+			args:   args{c: '.'},
+			want:   true,
+		},
+		{
+			name:   "not delim if in comment",
+			fields: fields{state: multiCommentOpened},
+			args:   args{c: '.'},
+			want:   false,
+		},
+		{
+			name:   "not delim if in string",
+			fields: fields{state: stringOpened},
+			args:   args{c: ' '},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenizer := &Tokenizer{
+				state: tt.fields.state,
+			}
+			if got := tokenizer.delim(tt.args.c); got != tt.want {
+				t.Errorf("Tokenizer.delim() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizer_bufToToken(t *testing.T) {
+	type fields struct {
+		state tokenizeState
+		buf   string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    Token
+		wantErr bool
+	}{
+		{
+			name:    "keyword",
+			fields:  fields{ordinal, "method"},
+			want:    KeywordToken("method"),
+			wantErr: false,
+		},
+		{
+			name:    "integer",
+			fields:  fields{ordinal, "982"},
+			want:    IntConstToken(982),
+			wantErr: false,
+		},
+		{
+			name:    "identifier",
+			fields:  fields{ordinal, "Main"},
+			want:    IdentifierToken("Main"),
+			wantErr: false,
+		},
+		{
+			name:    "invalid identifier",
+			fields:  fields{ordinal, "8var"},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenizer := &Tokenizer{
+				state: tt.fields.state,
+				buf:   tt.fields.buf,
+			}
+			got, err := tokenizer.bufToToken()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Tokenizer.bufToToken() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenizer.bufToToken() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizer_Tokenize_emptyStr(t *testing.T) {
+	src := "do f(\"\", \"\"\"a\");"
+	tokenizer := NewTokenizer(strings.NewReader(src), "Main.jack")
+	got, err := tokenizer.Tokenize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Token{
+		KeywordToken("do"),
+		IdentifierToken("f"),
+		SymbolToken("("),
+		StrConstToken(""),
+		SymbolToken(","),
+		StrConstToken(""),
+		StrConstToken("a"),
+		SymbolToken(")"),
+		SymbolToken(";"),
+	}
+	if diff := cmp.Diff([]Token(got), want, ignorePos); diff != "" {
+		t.Errorf("Tokenizer.Tokenize() diff (-got +want)\n%s", diff)
+	}
+}
+
+func TestTokenizer_Tokenize_pos(t *testing.T) {
+	src := "class Main {\n  /* コメント */ field int x;\n\tlet s = \"\\\"a\"; let y\n= 1;\n}"
+	tokenizer := NewTokenizer(strings.NewReader(src), "Main.jack")
+	got, err := tokenizer.Tokenize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Token{
+		NewPosToken(KeywordToken("class"), Pos{"Main.jack", 1, 1, 0}, Pos{"Main.jack", 1, 6, 5}),
+		NewPosToken(IdentifierToken("Main"), Pos{"Main.jack", 1, 7, 6}, Pos{"Main.jack", 1, 11, 10}),
+		NewPosToken(SymbolToken("{"), Pos{"Main.jack", 1, 12, 11}, Pos{"Main.jack", 1, 13, 12}),
+		NewPosToken(KeywordToken("field"), Pos{"Main.jack", 2, 14, 34}, Pos{"Main.jack", 2, 19, 39}),
+		NewPosToken(KeywordToken("int"), Pos{"Main.jack", 2, 20, 40}, Pos{"Main.jack", 2, 23, 43}),
+		NewPosToken(IdentifierToken("x"), Pos{"Main.jack", 2, 24, 44}, Pos{"Main.jack", 2, 25, 45}),
+		NewPosToken(SymbolToken(";"), Pos{"Main.jack", 2, 25, 45}, Pos{"Main.jack", 2, 26, 46}),
+		NewPosToken(KeywordToken("let"), Pos{"Main.jack", 3, 2, 48}, Pos{"Main.jack", 3, 5, 51}),
+		NewPosToken(IdentifierToken("s"), Pos{"Main.jack", 3, 6, 52}, Pos{"Main.jack", 3, 7, 53}),
+		NewPosToken(SymbolToken("="), Pos{"Main.jack", 3, 8, 54}, Pos{"Main.jack", 3, 9, 55}),
+		NewPosToken(StrConstToken(`\"a`), Pos{"Main.jack", 3, 10, 56}, Pos{"Main.jack", 3, 15, 61}),
+		NewPosToken(SymbolToken(";"), Pos{"Main.jack", 3, 15, 61}, Pos{"Main.jack", 3, 16, 62}),
+		NewPosToken(KeywordToken("let"), Pos{"Main.jack", 3, 17, 63}, Pos{"Main.jack", 3, 20, 66}),
+		NewPosToken(IdentifierToken("y"), Pos{"Main.jack", 3, 21, 67}, Pos{"Main.jack", 3, 22, 68}),
+		NewPosToken(SymbolToken("="), Pos{"Main.jack", 4, 1, 69}, Pos{"Main.jack", 4, 2, 70}),
+		NewPosToken(IntConstToken(1), Pos{"Main.jack", 4, 3, 71}, Pos{"Main.jack", 4, 4, 72}),
+		NewPosToken(SymbolToken(";"), Pos{"Main.jack", 4, 4, 72}, Pos{"Main.jack", 4, 5, 73}),
+		NewPosToken(SymbolToken("}"), Pos{"Main.jack", 5, 1, 74}, Pos{"Main.jack", 5, 2, 75}),
+	}
+	if diff := cmp.Diff([]Token(got), want); diff != "" {
+		t.Errorf("Tokenizer.Tokenize() diff (-got +want)\n%s", diff)
+	}
+}
+
+func TestTokenizer_Tokenize_strError(t *testing.T) {
+	src := "class Main {\n  let s = \"ok \\\" ü\";\n}"
+	_, err := NewTokenizer(strings.NewReader(src), "Main.jack").Tokenize()
+	want := `Main.jack:2:18: character 'ü' is not in the Hack character set`
+	if err == nil || err.Error() != want {
+		t.Errorf("Tokenizer.Tokenize() error = %v, want %s", err, want)
+	}
+}
+
+func TestTokenizer_Tokenize_unterminatedStr(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "string to end of line",
+			src:  "class Main {\n  let s = \"abc;\n}",
+			want: `Main.jack:2:11: unterminated string constant`,
+		},
+		{
+			name: "non Hack character on a later line",
+			src:  "class Main {\n  let s = \"aaaaaaaaaaaa\n\xc3\xa9\";\n}",
+			want: `Main.jack:2:11: unterminated string constant`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTokenizer(strings.NewReader(tt.src), "Main.jack").Tokenize()
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Tokenizer.Tokenize() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizer_Tokenize_syntaxError(t *testing.T) {
+	src := "class Main {\n  let a = 1 + 99999;\n}"
+	_, err := NewTokenizer(strings.NewReader(src), "Main.jack").Tokenize()
+	var l SyntaxErrorList
+	if !errors.As(err, &l) || len(l) != 1 {
+		t.Fatalf("Tokenizer.Tokenize() error = %v, want a SyntaxErrorList of an error", err)
+	}
+	if want := (Pos{"Main.jack", 2, 15, 27}); l[0].Pos != want {
+		t.Errorf("SyntaxError.Pos = %v, want %v", l[0].Pos, want)
+	}
+	if want := "integer constant 99999 is out of range 0..32767"; l[0].Msg != want {
+		t.Errorf("SyntaxError.Msg = %v, want %v", l[0].Msg, want)
+	}
+}
+
+func TestTokenizer_Tokenize_intError(t *testing.T) {
+	src := "class Main {\n  let a = 1 + 99999;\n}"
+	_, err := NewTokenizer(strings.NewReader(src), "Main.jack").Tokenize()
+	want := `Main.jack:2:15: integer constant 99999 is out of range 0..32767`
+	if err == nil || err.Error() != want {
+		t.Errorf("Tokenizer.Tokenize() error = %v, want %s", err, want)
+	}
+}
+
+func TestTokenizer_SetExtensions(t *testing.T) {
+	for _, ext := range []bool{false, true} {
+		tokenizer := NewTokenizer(strings.NewReader("for break continue switch case default const enum"), "Main.jack")
+		tokenizer.SetExtensions(ext)
+		tokens, err := tokenizer.Tokenize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := IdentifierType
+		if ext {
+			want = KeywordType
+		}
+		for _, tok := range tokens {
+			if tok.Type() != want {
+				t.Errorf("Tokenizer.Tokenize() with extensions %t = %v of %v, want %v", ext, tok, tok.Type(), want)
+			}
+		}
+	}
+}
+
+func TestTokenizer_Comments(t *testing.T) {
+	src := "/** doc\n * more */\nclass Main { // trailing\n  /* a */ /* b */\n}"
+	tokenizer := NewTokenizer(strings.NewReader(src), "Main.jack")
+	if _, err := tokenizer.Tokenize(); err != nil {
+		t.Fatal(err)
+	}
+	want := []Comment{
+		{Text: "/** doc\n * more */", P: Pos{"Main.jack", 1, 1, 0}, E: Pos{"Main.jack", 2, 11, 18}},
+		{Text: "// trailing", P: Pos{"Main.jack", 3, 14, 32}, E: Pos{"Main.jack", 3, 25, 43}},
+		{Text: "/* a */", P: Pos{"Main.jack", 4, 3, 46}, E: Pos{"Main.jack", 4, 10, 53}},
+		{Text: "/* b */", P: Pos{"Main.jack", 4, 11, 54}, E: Pos{"Main.jack", 4, 18, 61}},
+	}
+	if diff := cmp.Diff(tokenizer.Comments(), want); diff != "" {
+		t.Errorf("Tokenizer.Comments() diff (-got +want)\n%s", diff)
+	}
+}
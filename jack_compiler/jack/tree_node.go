@@ -1,7 +1,8 @@
-package main
+package jack
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -16,6 +17,8 @@ type TreeNode interface {
 	SetMeta(parent NodeType, grandParent NodeType, s *SymbolTableEntry) error
 	Meta() *IDMeta
 	Xml() string
+	Pos() Pos
+	End() Pos
 }
 
 type InnerNode struct {
@@ -65,6 +68,16 @@ func (n *InnerNode) Meta() *IDMeta {
 	return nil
 }
 
+// Pos returns the position of the first token under the node.
+func (n *InnerNode) Pos() Pos {
+	return firstPos(n.Children)
+}
+
+// End returns the end position of the last token under the node.
+func (n *InnerNode) End() Pos {
+	return lastEnd(n.Children)
+}
+
 func (n *InnerNode) Xml() string {
 	res := []string{}
 	if n.XMLMarkup {
@@ -87,6 +100,14 @@ func NewClassVarDecNode() *InnerNode {
 	return NewInnerNode(ClassVarDecType, "classVarDec", true)
 }
 
+func NewConstDecNode() *InnerNode {
+	return NewInnerNode(ConstDecType, "constDec", true)
+}
+
+func NewEnumDecNode() *InnerNode {
+	return NewInnerNode(EnumDecType, "enumDec", true)
+}
+
 func NewTypeNode() *OneChildNode {
 	return NewOneChildNode(TypeType, "type", false)
 }
@@ -143,6 +164,26 @@ func NewDoStatementNode() *InnerNode {
 	return NewInnerNode(DoStatementType, "doStatement", true)
 }
 
+func NewForStatementNode() *InnerNode {
+	return NewInnerNode(ForStatementType, "forStatement", true)
+}
+
+func NewBreakStatementNode() *InnerNode {
+	return NewInnerNode(BreakStatementType, "breakStatement", true)
+}
+
+func NewContinueStatementNode() *InnerNode {
+	return NewInnerNode(ContinueStatementType, "continueStatement", true)
+}
+
+func NewSwitchStatementNode() *InnerNode {
+	return NewInnerNode(SwitchStatementType, "switchStatement", true)
+}
+
+func NewSwitchCaseNode() *InnerNode {
+	return NewInnerNode(SwitchCaseType, "switchCase", true)
+}
+
 func NewReturnStatementNode() *InnerNode {
 	return NewInnerNode(ReturnStatementType, "returnStatement", true)
 }
@@ -226,6 +267,14 @@ func (n *OneChildNode) Meta() *IDMeta {
 	return n.Children[0].Meta()
 }
 
+func (n *OneChildNode) Pos() Pos {
+	return firstPos(n.Children)
+}
+
+func (n *OneChildNode) End() Pos {
+	return lastEnd(n.Children)
+}
+
 func (n *OneChildNode) Xml() string {
 	res := []string{}
 	if n.XMLMarkup {
@@ -240,12 +289,17 @@ func (n *OneChildNode) Xml() string {
 	return strings.Join(res, "\n")
 }
 
+// IDAttr makes Xml of identifier nodes output the attributes of IDMeta.
+var IDAttr = false
+
 type LeafNode struct {
 	Typ       NodeType
 	N         string
 	V         string
 	XMLMarkup bool
 	IDMeta    *IDMeta
+	P         Pos
+	E         Pos
 }
 
 type IDMeta struct {
@@ -263,6 +317,7 @@ const (
 	IdCatField
 	IdCatClass
 	IdCatSub
+	IdCatConst
 )
 
 func (i IdCategory) String() string {
@@ -279,6 +334,8 @@ func (i IdCategory) String() string {
 		return "IdCatClass"
 	case IdCatSub:
 		return "IdCatSub"
+	case IdCatConst:
+		return "IdCatConst"
 	}
 	return "Invalid"
 }
@@ -287,6 +344,7 @@ type SymbolInfo struct {
 	Kind  VarKind
 	Type  string
 	Index int
+	Value int
 }
 
 func NewLeafNode(typ NodeType, name string, x bool) *LeafNode {
@@ -350,13 +408,15 @@ func (n *LeafNode) SetMeta(parent NodeType, grandParent NodeType, s *SymbolTable
 			meta.Category = IdCatArg
 		case Var:
 			meta.Category = IdCatVar
+		case Const:
+			meta.Category = IdCatConst
 		}
 	}
 
 	// Declaration
 	meta.Declaration = false
 	switch grandParent {
-	case ClassType, ClassVarDecType, SubroutineDecType, ParameterListType, VarDecType:
+	case ClassType, ClassVarDecType, ConstDecType, EnumDecType, SubroutineDecType, ParameterListType, VarDecType:
 		meta.Declaration = true
 	}
 
@@ -366,6 +426,7 @@ func (n *LeafNode) SetMeta(parent NodeType, grandParent NodeType, s *SymbolTable
 			Kind:  s.Kind,
 			Type:  s.Typ,
 			Index: s.Index,
+			Value: s.Value,
 		}
 	}
 
@@ -378,8 +439,16 @@ func (n *LeafNode) Meta() *IDMeta {
 	return n.IDMeta
 }
 
+func (n *LeafNode) Pos() Pos {
+	return n.P
+}
+
+func (n *LeafNode) End() Pos {
+	return n.E
+}
+
 func (n *LeafNode) Xml() string {
-	if idAttr && n.Type() == IdentifierType {
+	if IDAttr && n.Type() == IdentifierType {
 		if n.IDMeta.SymbolInfo != nil {
 			return fmt.Sprintf("<%s category=\"%s\" declaration=\"%t\" kind=\"%s\" type=\"%s\" index=\"%d\">%s</%s>", n.Name(), n.IDMeta.Category, n.IDMeta.Declaration, n.IDMeta.SymbolInfo.Kind, n.IDMeta.SymbolInfo.Type, n.IDMeta.SymbolInfo.Index, escapeXml(n.Value()), n.Name())
 		}
@@ -391,5 +460,87 @@ func (n *LeafNode) Xml() string {
 func AdaptTokenToNode(token Token) TreeNode {
 	node := NewLeafNode(token.Type(), token.Name(), true)
 	node.SetValue(token.String())
+	node.P = token.Pos()
+	node.E = token.End()
 	return node
 }
+
+func firstPos(nodes []TreeNode) Pos {
+	for _, n := range nodes {
+		if p := n.Pos(); p.IsValid() {
+			return p
+		}
+	}
+	return Pos{}
+}
+
+func lastEnd(nodes []TreeNode) Pos {
+	for i := len(nodes) - 1; i >= 0; i-- {
+		if p := nodes[i].End(); p.IsValid() {
+			return p
+		}
+	}
+	return Pos{}
+}
+
+// isArrayLet reports whether the let statement assigns to an array element, `let a[i] = v`.
+func isArrayLet(let TreeNode) bool {
+	return isSymbol(let.ChildNodes()[2], "[")
+}
+
+// letValue returns the expression assigned by the let statement, which may lack `;` in for statements.
+func letValue(let TreeNode) TreeNode {
+	if isArrayLet(let) {
+		return let.ChildNodes()[6]
+	}
+	return let.ChildNodes()[3]
+}
+
+// condition returns the condition of an if, while or for statement.
+func condition(stmt TreeNode) TreeNode {
+	if stmt.Type() == ForStatementType {
+		return stmt.ChildNodes()[3]
+	}
+	return stmt.ChildNodes()[2]
+}
+
+// caseValue returns the value of the constant of a switch case, which is an integer or character constant optionally negated,
+// true or false, or a constant of the class being parsed.
+func caseValue(term TreeNode) (int, bool) {
+	children := term.ChildNodes()
+	switch {
+	case isConstantRef(term):
+		if s := children[2].Meta().SymbolInfo; s != nil {
+			return s.Value, true
+		}
+	case children[0].Type() == IntConstType:
+		v, err := strconv.Atoi(children[0].Value())
+		return v, err == nil
+	case children[0].Type() == UnaryOpType && children[0].Value() == "-" && children[1].ChildNodes()[0].Type() == IntConstType:
+		v, err := strconv.Atoi(children[1].ChildNodes()[0].Value())
+		return wrapInt(-v), err == nil
+	case children[0].Type() == KeywordConstantType && children[0].Value() == "true":
+		return -1, true
+	case children[0].Type() == KeywordConstantType && children[0].Value() == "false":
+		return 0, true
+	}
+	return 0, false
+}
+
+// isConstantRef reports whether the term is `Class.NAME` referring to a constant or `Enum.NAME` to an enum member.
+func isConstantRef(term TreeNode) bool {
+	children := term.ChildNodes()
+	return len(children) == 3 && children[0].Type() == ClassNameType
+}
+
+// elseBranch returns the statements of the else clause of an if statement, the if statement of `else if`, or nil.
+func elseBranch(ifStmt TreeNode) TreeNode {
+	children := ifStmt.ChildNodes()
+	switch len(children) {
+	case 9:
+		return children[8]
+	case 11:
+		return children[9]
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package jack
+
+// TypeCheck checks the types of assignments, arguments, return values, conditions and operands in the class.
+// Types are inferred from the declarations and the signatures in the program. Array elements have unknown type,
+// which matches any type. The problems are reported as warnings unless asError is true.
+func (p *Program) TypeCheck(class TreeNode, asError bool) error {
+	c := &typeChecker{prog: p, warning: !asError}
+	c.check(class)
+	return c.errs.Err()
+}
+
+type typeChecker struct {
+	prog      *Program
+	className string
+	sub       *SubroutineSig
+	warning   bool
+	errs      SemanticErrorList
+}
+
+func (c *typeChecker) check(node TreeNode) {
+	switch node.Type() {
+	case ClassType:
+		for _, n := range node.ChildNodes() {
+			if n.Type() == ClassNameType {
+				c.className = n.Value()
+			}
+		}
+	case SubroutineDecType:
+		c.sub = newSubroutineSig(c.className, node)
+	case ConstDecType:
+		children := node.ChildNodes()
+		if t, vt := children[1].Value(), c.typeOf(children[4]); !assignable(vt, t) {
+			c.errorf(children[4].Pos(), "cannot assign %s to %s of type %s", vt, children[2].Value(), t)
+		}
+		return
+	case LetStatementType:
+		c.checkLet(node)
+		return
+	case IfStatementType, WhileStatementType, ForStatementType:
+		cond := condition(node)
+		if t := c.typeOf(cond); !isBooleanish(t) {
+			c.errorf(cond.Pos(), "condition of %s has type %s, want boolean", node.ChildNodes()[0].Value(), t)
+		}
+	case DoStatementType:
+		c.typeOf(node.ChildNodes()[1])
+		return
+	case ReturnStatementType:
+		c.checkReturn(node)
+		return
+	}
+
+	for _, n := range node.ChildNodes() {
+		c.check(n)
+	}
+}
+
+func (c *typeChecker) checkLet(node TreeNode) {
+	children := node.ChildNodes()
+	varName := children[1]
+	value := letValue(node)
+	vt := c.typeOf(value)
+
+	if isArrayLet(node) {
+		// array element has unknown type
+		c.checkIndex(varName, children[3])
+		return
+	}
+	if t := varType(varName); !assignable(vt, t) {
+		c.errorf(value.Pos(), "cannot assign %s to %s of type %s", vt, varName.Value(), t)
+	}
+}
+
+func (c *typeChecker) checkReturn(node TreeNode) {
+	children := node.ChildNodes()
+	if len(children) != 3 {
+		return
+	}
+	value := children[1]
+	vt := c.typeOf(value)
+	if c.sub == nil || c.sub.Type == "void" {
+		return
+	}
+	if !assignable(vt, c.sub.Type) {
+		c.errorf(value.Pos(), "cannot return %s from %s returning %s", vt, c.sub.FullName(), c.sub.Type)
+	}
+}
+
+func (c *typeChecker) checkIndex(varName TreeNode, index TreeNode) {
+	if t := varType(varName); isPrimitive(t) {
+		c.errorf(varName.Pos(), "%s of type %s is not an array", varName.Value(), t)
+	}
+	if t := c.typeOf(index); !isNumeric(t) {
+		c.errorf(index.Pos(), "array index has type %s, want int", t)
+	}
+}
+
+// typeOf returns the type of an expression, term or subroutine call. An empty string means unknown type.
+func (c *typeChecker) typeOf(node TreeNode) string {
+	switch node.Type() {
+	case ExpressionType:
+		return c.expressionType(node.ChildNodes())
+	case SubroutineCallType:
+		return c.callType(node)
+	case TermType:
+		return c.termType(node)
+	}
+	return ""
+}
+
+// expressionType applies the binary ops in the order the compiler does.
+func (c *typeChecker) expressionType(exps []TreeNode) string {
+	prec := func(op TreeNode) int {
+		if !c.prog.Precedence {
+			return 0
+		}
+		return OpPrecedence(op.Value())
+	}
+	types := []string{c.typeOf(exps[0])}
+	var ops []TreeNode
+	apply := func() {
+		n := len(types)
+		types = append(types[:n-2], c.binaryOpType(ops[len(ops)-1], types[n-2], types[n-1]))
+		ops = ops[:len(ops)-1]
+	}
+	for i := 1; i+1 < len(exps); i += 2 {
+		for len(ops) > 0 && prec(ops[len(ops)-1]) >= prec(exps[i]) {
+			apply()
+		}
+		ops = append(ops, exps[i])
+		types = append(types, c.typeOf(exps[i+1]))
+	}
+	for len(ops) > 0 {
+		apply()
+	}
+	return types[0]
+}
+
+func (c *typeChecker) termType(node TreeNode) string {
+	children := node.ChildNodes()
+	first := children[0]
+	switch first.Type() {
+	case IntConstType:
+		return "int"
+	case StrConstType:
+		return "String"
+	case KeywordConstantType:
+		switch first.Value() {
+		case "true", "false":
+			return "boolean"
+		case "null":
+			return "null"
+		case "this":
+			return c.className
+		}
+	case VarNameType:
+		if len(children) == 4 {
+			c.checkIndex(first, children[2])
+			return ""
+		}
+		return varType(first)
+	case ClassNameType: // Class.NAME
+		if s := c.prog.constant(node); s != nil {
+			return s.Type
+		}
+		return ""
+	case SubroutineCallType:
+		return c.typeOf(first)
+	case SymbolType: // (expression)
+		return c.typeOf(children[1])
+	case UnaryOpType:
+		t := c.typeOf(children[1])
+		if first.Value() == "~" && (t == "boolean" || t == "") {
+			return t
+		}
+		if !isNumeric(t) {
+			c.errorf(first.Pos(), "operator %s applied to %s", first.Value(), t)
+		}
+		return "int"
+	}
+	return ""
+}
+
+func (c *typeChecker) binaryOpType(op TreeNode, lt, rt string) string {
+	switch op.Value() {
+	case "+", "-", "*", "/", "<", ">":
+		if !isNumeric(lt) || !isNumeric(rt) {
+			c.errorf(op.Pos(), "operator %s applied to %s and %s", op.Value(), lt, rt)
+		}
+		if op.Value() == "<" || op.Value() == ">" {
+			return "boolean"
+		}
+		return "int"
+	case "&", "|":
+		if lt == "" || rt == "" {
+			return ""
+		}
+		if lt == "boolean" && rt == "boolean" {
+			return "boolean"
+		}
+		if !isNumeric(lt) || !isNumeric(rt) {
+			c.errorf(op.Pos(), "operator %s applied to %s and %s", op.Value(), lt, rt)
+			return ""
+		}
+		return "int"
+	case "=":
+		if !assignable(lt, rt) && !assignable(rt, lt) {
+			c.errorf(op.Pos(), "operator %s applied to %s and %s", op.Value(), lt, rt)
+		}
+		return "boolean"
+	}
+	return ""
+}
+
+// callType checks the arguments of the call and returns the type of the return value.
+func (c *typeChecker) callType(node TreeNode) string {
+	var args []TreeNode
+	for _, n := range node.ChildNodes() {
+		if n.Type() != ExpressionListType {
+			continue
+		}
+		for _, e := range n.ChildNodes() {
+			if e.Type() == ExpressionType {
+				args = append(args, e)
+			}
+		}
+	}
+
+	sig := c.prog.callee(c.className, node)
+	for i, arg := range args {
+		t := c.typeOf(arg)
+		if sig == nil || i >= len(sig.Params) {
+			continue
+		}
+		if !assignable(t, sig.Params[i]) {
+			c.errorf(arg.Pos(), "argument %d of %s has type %s, want %s", i+1, sig.FullName(), t, sig.Params[i])
+		}
+	}
+	if sig == nil {
+		return ""
+	}
+	return sig.Type
+}
+
+func (c *typeChecker) errorf(pos Pos, format string, a ...interface{}) {
+	e := semanticError(pos, format, a...)
+	e.Warning = c.warning
+	c.errs = append(c.errs, e)
+}
+
+func varType(varName TreeNode) string {
+	meta := varName.Meta()
+	if meta == nil || meta.SymbolInfo == nil {
+		return ""
+	}
+	return meta.SymbolInfo.Type
+}
+
+func isPrimitive(t string) bool {
+	return t == "int" || t == "char" || t == "boolean"
+}
+
+func isNumeric(t string) bool {
+	return t == "int" || t == "char" || t == ""
+}
+
+func isBooleanish(t string) bool {
+	return t == "boolean" || isNumeric(t)
+}
+
+// assignable reports whether a value of type from can be stored to a variable of type to.
+// int and char are interchangeable, null is any object, and Array is an untyped pointer compatible with any object.
+func assignable(from, to string) bool {
+	switch {
+	case from == "" || to == "":
+		return true
+	case from == "void" || to == "void":
+		return false
+	case from == to:
+		return true
+	case isNumeric(from) && isNumeric(to):
+		return true
+	case isPrimitive(from) || isPrimitive(to):
+		return false
+	case from == "null":
+		return true
+	case from == "Array" || to == "Array":
+		return true
+	}
+	return false
+}
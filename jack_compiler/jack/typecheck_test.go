@@ -0,0 +1,94 @@
+package jack
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProgram_TypeCheck(t *testing.T) {
+	foo := "class Foo {\n  constructor Foo new() { return this; }\n  method int get(int a, boolean b) { return a; }\n}"
+	tests := []struct {
+		name       string
+		main       string
+		asError    bool
+		precedence bool
+		want       string
+	}{
+		{
+			name: "valid",
+			main: "class Main {\n  function void main() {\n    var Foo foo;\n    var Array a;\n    var char c;\n    let foo = Foo.new();\n    let foo = null;\n    let a = Array.new(3);\n    let a[1] = foo;\n    let foo = a[1];\n    let c = 65 + foo.get(c, 1 < 2);\n    if (c) { let c = c; }\n    while (~(c = 0) & true) { let c = -c; }\n    return;\n  }\n}",
+			want: "",
+		},
+		{
+			name: "assignment",
+			main: "class Main {\n  function void main() {\n    var Foo foo;\n    var int i;\n    let foo = 1;\n    let i = \"s\";\n    let i = Main.f();\n    return;\n  }\n  function void f() { return; }\n}",
+			want: "Main.jack:5:15: warning: cannot assign int to foo of type Foo\n" +
+				"Main.jack:6:13: warning: cannot assign String to i of type int\n" +
+				"Main.jack:7:13: warning: cannot assign void to i of type int",
+		},
+		{
+			name: "constants",
+			main: "class Main {\n  const boolean DEBUG = 1;\n  const char A = 'A';\n  enum Dir { UP, DOWN }\n  function void main() {\n    var boolean b;\n    let b = Dir.UP;\n    let b = Main.A + 1;\n    if (Main.DEBUG) { let b = Main.DEBUG; }\n    return;\n  }\n}",
+			want: "Main.jack:2:25: warning: cannot assign int to DEBUG of type boolean\n" +
+				"Main.jack:7:13: warning: cannot assign int to b of type boolean\n" +
+				"Main.jack:8:13: warning: cannot assign int to b of type boolean",
+		},
+		{
+			name: "arguments and return",
+			main: "class Main {\n  function Foo main() {\n    var Foo foo;\n    do foo.get(true, 1);\n    return 1;\n  }\n}",
+			want: "Main.jack:4:16: warning: argument 1 of Foo.get has type boolean, want int\n" +
+				"Main.jack:4:22: warning: argument 2 of Foo.get has type int, want boolean\n" +
+				"Main.jack:5:12: warning: cannot return int from Main.main returning Foo",
+		},
+		{
+			name: "conditions and operands",
+			main: "class Main {\n  function void main() {\n    var Foo foo;\n    var int i;\n    var boolean b;\n    if (foo) { let i = b + 1; }\n    while (\"s\") { let b = i & b; }\n    let i = i[0];\n    return;\n  }\n}",
+			want: "Main.jack:6:9: warning: condition of if has type Foo, want boolean\n" +
+				"Main.jack:6:26: warning: operator + applied to boolean and int\n" +
+				"Main.jack:7:12: warning: condition of while has type String, want boolean\n" +
+				"Main.jack:7:29: warning: operator & applied to int and boolean\n" +
+				"Main.jack:8:13: warning: i of type int is not an array",
+		},
+		{
+			name: "left to right",
+			main: "class Main {\n  function void main() {\n    var int i;\n    var boolean b;\n    let b = i < i + 1;\n    return;\n  }\n}",
+			want: "Main.jack:5:19: warning: operator + applied to boolean and int\n" +
+				"Main.jack:5:13: warning: cannot assign int to b of type boolean",
+		},
+		{
+			name:       "precedence",
+			main:       "class Main {\n  function void main() {\n    var int i;\n    var boolean b;\n    let b = i < i + 1;\n    return;\n  }\n}",
+			precedence: true,
+			want:       "",
+		},
+		{
+			name:    "as error",
+			main:    "class Main {\n  function void main() {\n    var Foo foo;\n    let foo = 1;\n    return;\n  }\n}",
+			asError: true,
+			want:    "Main.jack:4:15: cannot assign int to foo of type Foo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog := NewProgram()
+			prog.Precedence = tt.precedence
+			var trees []*InnerNode
+			for _, src := range []string{foo, tt.main} {
+				tree := parseForTest(t, src, true)
+				if err := prog.AddClass(tree); err != nil {
+					t.Fatal(err)
+				}
+				trees = append(trees, tree)
+			}
+			var got []string
+			for _, tree := range trees {
+				if err := prog.TypeCheck(tree, tt.asError); err != nil {
+					got = append(got, err.Error())
+				}
+			}
+			if strings.Join(got, "\n") != tt.want {
+				t.Errorf("Program.TypeCheck() = %v, want %v", strings.Join(got, "\n"), tt.want)
+			}
+		})
+	}
+}
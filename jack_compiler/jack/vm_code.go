@@ -1,4 +1,4 @@
-package main
+package jack
 
 import "fmt"
 
@@ -76,16 +76,20 @@ func (v *VmCode) ret() string {
 	return "return"
 }
 
-func (v *VmCode) true() []string {
-	return []string{v.pushConstant(1), v.neg()}
+// minInt returns -32768, which is not the negation of a constant since constants are at most 32767.
+func (v *VmCode) minInt() []string {
+	return []string{v.pushConstant(32767), v.neg(), v.pushConstant(1), v.sub()}
 }
 
-func (v *VmCode) false() string {
-	return v.pushConstant(0)
-}
-
-func (v *VmCode) null() string {
-	return v.pushConstant(0)
+// constant returns a 16 bit value, negating for negative ones.
+func (v *VmCode) constant(i int) []string {
+	switch {
+	case i == -32768:
+		return v.minInt()
+	case i < 0:
+		return []string{v.pushConstant(-i), v.neg()}
+	}
+	return []string{v.pushConstant(i)}
 }
 
 func (v *VmCode) goTo(label string) string {
@@ -101,13 +105,16 @@ func (v *VmCode) label(label string) string {
 }
 
 func (v *VmCode) newStr(in string) []string {
+	// the tokenizer has validated the string
+	chars, _, _ := hackChars(in)
+
 	// refs Sys.vm:69
 	res := []string{
-		v.push("constant", len(in)),
+		v.push("constant", len(chars)),
 		v.call("String", "new", 1),
 	}
-	for _, r := range in {
-		res = append(res, v.pushConstant(int(r)))
+	for _, c := range chars {
+		res = append(res, v.pushConstant(c))
 		res = append(res, v.call("String", "appendChar", 2))
 	}
 	return res
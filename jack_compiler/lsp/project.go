@@ -0,0 +1,505 @@
+package lsp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/cou929/nand2tetris/jack_compiler/jack"
+)
+
+// project is the analysis of all .jack files in a directory, which are compiled together.
+type project struct {
+	docs  []*document
+	prog  *jack.Program
+	decls map[string]*ident // declarations by symbol key
+}
+
+// document is an analyzed source file. tree is nil if the class declaration itself is broken.
+type document struct {
+	path   string
+	src    []byte
+	tree   *jack.InnerNode
+	ends   map[int]jack.Pos // end of the token at each offset
+	diags  []Diagnostic
+	broken bool // has tokenize or syntax errors
+	idents []*ident
+}
+
+// ident is an identifier resolved to the symbol it refers to.
+// Symbols are identified by keys such as "class Main", "sub Main.main", "var Main.x" and "var Main.jack@12.i"
+// where local variables are qualified by the position of the subroutine declaration.
+type ident struct {
+	doc   *document
+	node  jack.TreeNode
+	key   string // empty if undefined
+	decl  bool
+	hover string
+}
+
+// loadProject analyzes the files in the directory. Files in open, which are edited in the client, override the ones on disk.
+func loadProject(dir string, open map[string][]byte) (*project, error) {
+	srcs := make(map[string][]byte)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("[loadProject] %w", err)
+	}
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".jack" {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("[loadProject] %w", err)
+		}
+		srcs[path] = src
+	}
+	for path, src := range open {
+		if filepath.Dir(path) == dir && filepath.Ext(path) == ".jack" {
+			srcs[path] = src
+		}
+	}
+
+	p := &project{
+		prog:  jack.NewProgram(),
+		decls: make(map[string]*ident),
+	}
+	for path, src := range srcs {
+		p.docs = append(p.docs, parseDocument(path, src))
+	}
+	sort.Slice(p.docs, func(i, j int) bool {
+		return p.docs[i].path < p.docs[j].path
+	})
+
+	for _, d := range p.docs {
+		if d.tree != nil {
+			p.report(p.prog.AddClass(d.tree))
+		}
+	}
+	for _, d := range p.docs {
+		if d.tree != nil && !d.broken {
+			p.report(p.prog.Check(d.tree))
+			p.report(p.prog.FlowCheck(d.tree, true))
+		}
+	}
+	for _, d := range p.docs {
+		p.index(d)
+	}
+	return p, nil
+}
+
+func parseDocument(path string, src []byte) *document {
+	d := &document{
+		path: path,
+		src:  src,
+		ends: make(map[int]jack.Pos),
+	}
+	tokens, err := jack.NewTokenizer(bytes.NewReader(src), path).Tokenize()
+	if err != nil {
+		d.broken = true
+		d.syntaxErrors(err)
+		return d
+	}
+	for _, t := range tokens {
+		d.ends[t.Pos().Offset] = t.End()
+	}
+
+	tree, err := jack.NewParser().Parse(tokens)
+	d.tree = tree
+	if err != nil {
+		d.broken = true
+		d.syntaxErrors(err)
+	}
+	return d
+}
+
+// syntaxErrors adds the diagnostics of the errors of the tokenizer or the parser.
+func (d *document) syntaxErrors(err error) {
+	var l jack.SyntaxErrorList
+	if !errors.As(err, &l) {
+		d.diags = append(d.diags, Diagnostic{Severity: severityError, Source: "jack", Message: err.Error()})
+		return
+	}
+	for _, se := range l {
+		msg := strings.TrimPrefix(se.Error(), se.Pos.String()+": ")
+		d.diags = append(d.diags, d.diagnostic(se.Pos, severityError, msg))
+	}
+}
+
+// diagnostic makes a diagnostic spanning the token at the position.
+func (d *document) diagnostic(pos jack.Pos, severity int, msg string) Diagnostic {
+	end, ok := d.ends[pos.Offset]
+	if !ok {
+		end = pos
+	}
+	return Diagnostic{
+		Range:    d.rangeOf(pos, end),
+		Severity: severity,
+		Source:   "jack",
+		Message:  msg,
+	}
+}
+
+// report adds the semantic errors to the documents they are found in.
+func (p *project) report(err error) {
+	if err == nil {
+		return
+	}
+	for _, se := range err.(jack.SemanticErrorList) {
+		d := p.doc(se.Pos.File)
+		if d == nil {
+			continue
+		}
+		severity := severityError
+		if se.Warning {
+			severity = severityWarning
+		}
+		d.diags = append(d.diags, d.diagnostic(se.Pos, severity, se.Msg))
+	}
+}
+
+func (p *project) doc(path string) *document {
+	for _, d := range p.docs {
+		if d.path == path {
+			return d
+		}
+	}
+	return nil
+}
+
+// index resolves the identifiers of the document.
+func (p *project) index(d *document) {
+	if d.tree == nil {
+		return
+	}
+	className := ""
+	for _, n := range d.tree.ChildNodes() {
+		if n.Type() == jack.ClassNameType {
+			className = n.Value()
+		}
+	}
+
+	var walk func(n, parent, sub jack.TreeNode)
+	walk = func(n, parent, sub jack.TreeNode) {
+		switch n.Type() {
+		case jack.SubroutineDecType:
+			sub = n
+		case jack.ClassNameType, jack.SubroutineNameType, jack.VarNameType:
+			id := p.resolve(d, n, parent, sub, className)
+			d.idents = append(d.idents, id)
+			if _, ok := p.decls[id.key]; id.decl && id.key != "" && !ok {
+				p.decls[id.key] = id
+			}
+			return
+		}
+		for _, c := range n.ChildNodes() {
+			walk(c, n, sub)
+		}
+	}
+	walk(d.tree, nil, nil)
+}
+
+func (p *project) resolve(d *document, n, parent, sub jack.TreeNode, className string) *ident {
+	id := &ident{doc: d, node: n}
+	name := n.Value()
+	switch n.Type() {
+	case jack.ClassNameType:
+		id.key = "class " + name
+		id.decl = parent.Type() == jack.ClassType
+		if _, ok := p.prog.Classes[name]; ok {
+			id.hover = codeBlock("class " + name)
+		}
+	case jack.SubroutineNameType:
+		cls := className
+		id.decl = parent.Type() == jack.SubroutineDecType
+		if !id.decl {
+			cls = calleeClass(parent, className)
+		}
+		id.key = fmt.Sprintf("sub %s.%s", cls, name)
+		if sig := p.subroutine(cls, name); sig != nil {
+			id.hover = codeBlock(signature(sig))
+		}
+	case jack.VarNameType:
+		switch parent.Type() {
+		case jack.ClassVarDecType, jack.ParameterListType, jack.VarDecType:
+			id.decl = true
+		}
+		if n.Meta() == nil || n.Meta().SymbolInfo == nil {
+			return id
+		}
+		info := n.Meta().SymbolInfo
+		if (info.Kind == jack.Argument || info.Kind == jack.Var) && sub != nil {
+			id.key = fmt.Sprintf("var %s@%d.%s", d.path, sub.Pos().Offset, name)
+		} else {
+			id.key = fmt.Sprintf("var %s.%s", className, name)
+		}
+		id.hover = fmt.Sprintf("%s\nkind: %v, type: %s, index: %d",
+			codeBlock(fmt.Sprintf("%s %s %s", strings.ToLower(info.Kind.String()), info.Type, name)), info.Kind, info.Type, info.Index)
+	}
+	return id
+}
+
+// calleeClass returns the class of the subroutine call: the class name, the type of the variable or the class itself.
+func calleeClass(call jack.TreeNode, className string) string {
+	for _, n := range call.ChildNodes() {
+		switch n.Type() {
+		case jack.ClassNameType:
+			return n.Value()
+		case jack.VarNameType:
+			if n.Meta() != nil && n.Meta().SymbolInfo != nil {
+				return n.Meta().SymbolInfo.Type
+			}
+			return ""
+		}
+	}
+	return className
+}
+
+func (p *project) subroutine(class, name string) *jack.SubroutineSig {
+	if cs, ok := p.prog.Classes[class]; ok {
+		return cs.Subroutines[name]
+	}
+	return nil
+}
+
+func signature(sig *jack.SubroutineSig) string {
+	return fmt.Sprintf("%s %s %s(%s)", strings.ToLower(sig.Kind.String()), sig.Type, sig.FullName(), strings.Join(sig.Params, ", "))
+}
+
+func codeBlock(s string) string {
+	return "```jack\n" + s + "\n```"
+}
+
+// identAt returns the identifier at the offset, including the one just before the cursor.
+func (d *document) identAt(offset int) *ident {
+	for _, id := range d.idents {
+		if id.node.Pos().Offset <= offset && offset <= id.node.End().Offset {
+			return id
+		}
+	}
+	return nil
+}
+
+// references returns the identifiers referring to the symbol in all documents.
+func (p *project) references(key string, includeDecl bool) []*ident {
+	var res []*ident
+	for _, d := range p.docs {
+		for _, id := range d.idents {
+			if id.key == key && (includeDecl || !id.decl) {
+				res = append(res, id)
+			}
+		}
+	}
+	return res
+}
+
+// complete returns the members of the class or variable before the dot at the offset.
+// Methods are offered for variables and functions and constructors for classes.
+func (p *project) complete(d *document, offset int) []CompletionItem {
+	src := d.src
+	i := offset
+	for i > 0 && isIdentByte(src[i-1]) {
+		i--
+	}
+	if i == 0 || src[i-1] != '.' {
+		return nil
+	}
+	j := i - 1
+	for j > 0 && isIdentByte(src[j-1]) {
+		j--
+	}
+	recv := string(src[j : i-1])
+	if recv == "" {
+		return nil
+	}
+
+	cs, methods := p.prog.Classes[d.varTypes(offset)[recv]], true
+	if cs == nil {
+		cs, methods = p.prog.Classes[recv], false
+	}
+	if cs == nil {
+		return nil
+	}
+	var res []CompletionItem
+	for _, sig := range cs.Subroutines {
+		item := CompletionItem{Label: sig.Name, Detail: signature(sig)}
+		switch {
+		case methods && sig.Kind == jack.Method:
+			item.Kind = completionMethod
+		case !methods && sig.Kind == jack.Function:
+			item.Kind = completionFunction
+		case !methods && sig.Kind == jack.Constructor:
+			item.Kind = completionConstructor
+		default:
+			continue
+		}
+		res = append(res, item)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Label < res[j].Label
+	})
+	return res
+}
+
+// varTypes returns the types of the variables in scope at the offset.
+func (d *document) varTypes(offset int) map[string]string {
+	res := make(map[string]string)
+	if d.tree == nil {
+		return res
+	}
+	var sub jack.TreeNode
+	for _, n := range d.tree.ChildNodes() {
+		switch n.Type() {
+		case jack.ClassVarDecType:
+			addVarTypes(n, res)
+		case jack.SubroutineDecType:
+			if n.Pos().Offset <= offset {
+				sub = n
+			}
+		}
+	}
+	if sub == nil {
+		return res
+	}
+	for _, n := range sub.ChildNodes() {
+		switch n.Type() {
+		case jack.ParameterListType:
+			addVarTypes(n, res)
+		case jack.SubroutineBodyType:
+			for _, b := range n.ChildNodes() {
+				if b.Type() == jack.VarDecType {
+					addVarTypes(b, res)
+				}
+			}
+		}
+	}
+	return res
+}
+
+func addVarTypes(dec jack.TreeNode, types map[string]string) {
+	typ := ""
+	for _, n := range dec.ChildNodes() {
+		switch n.Type() {
+		case jack.TypeType:
+			typ = n.Value()
+		case jack.VarNameType:
+			types[n.Value()] = typ
+		}
+	}
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// symbols returns the outline of the class.
+func (d *document) symbols() []DocumentSymbol {
+	if d.tree == nil {
+		return []DocumentSymbol{}
+	}
+	class := DocumentSymbol{
+		Kind:  symbolClass,
+		Range: d.rangeOf(d.tree.Pos(), d.tree.End()),
+	}
+	for _, n := range d.tree.ChildNodes() {
+		switch n.Type() {
+		case jack.ClassNameType:
+			class.Name = n.Value()
+			class.SelectionRange = d.rangeOf(n.Pos(), n.End())
+		case jack.ClassVarDecType:
+			kind := n.ChildNodes()[0].Value()
+			typ := ""
+			for _, v := range n.ChildNodes() {
+				switch v.Type() {
+				case jack.TypeType:
+					typ = v.Value()
+				case jack.VarNameType:
+					s := DocumentSymbol{
+						Name:           v.Value(),
+						Detail:         kind + " " + typ,
+						Kind:           symbolVariable,
+						Range:          d.rangeOf(n.Pos(), n.End()),
+						SelectionRange: d.rangeOf(v.Pos(), v.End()),
+					}
+					if kind == "field" {
+						s.Kind = symbolField
+					}
+					class.Children = append(class.Children, s)
+				}
+			}
+		case jack.SubroutineDecType:
+			var params []string
+			s := DocumentSymbol{
+				Kind:  symbolFunction,
+				Range: d.rangeOf(n.Pos(), n.End()),
+			}
+			for _, c := range n.ChildNodes() {
+				switch c.Type() {
+				case jack.SubroutineNameType:
+					s.Name = c.Value()
+					s.SelectionRange = d.rangeOf(c.Pos(), c.End())
+				case jack.ParameterListType:
+					for _, p := range c.ChildNodes() {
+						if p.Type() == jack.TypeType {
+							params = append(params, p.Value())
+						}
+					}
+				}
+			}
+			switch n.ChildNodes()[0].Value() {
+			case "method":
+				s.Kind = symbolMethod
+			case "constructor":
+				s.Kind = symbolConstructor
+			}
+			s.Detail = fmt.Sprintf("%s %s(%s)", n.ChildNodes()[1].Value(), s.Name, strings.Join(params, ", "))
+			class.Children = append(class.Children, s)
+		}
+	}
+	return []DocumentSymbol{class}
+}
+
+// rangeOf converts the positions to a range of LSP.
+func (d *document) rangeOf(start, end jack.Pos) Range {
+	return Range{
+		Start: position(d.src, start.Offset),
+		End:   position(d.src, end.Offset),
+	}
+}
+
+// position converts the byte offset to the line and the UTF-16 character offset.
+func position(src []byte, offset int) Position {
+	if offset > len(src) {
+		offset = len(src)
+	}
+	start := bytes.LastIndexByte(src[:offset], '\n') + 1
+	return Position{
+		Line:      bytes.Count(src[:offset], []byte("\n")),
+		Character: len(utf16.Encode([]rune(string(src[start:offset])))),
+	}
+}
+
+// offsetOf converts the position of LSP to the byte offset. Positions past the end of a line are at the end of it.
+func offsetOf(src []byte, pos Position) int {
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		i := bytes.IndexByte(src[offset:], '\n')
+		if i < 0 {
+			return len(src)
+		}
+		offset += i + 1
+	}
+	for c := 0; c < pos.Character && offset < len(src) && src[offset] != '\n'; {
+		r, size := utf8.DecodeRune(src[offset:])
+		c += len(utf16.Encode([]rune{r}))
+		offset += size
+	}
+	return offset
+}
@@ -0,0 +1,286 @@
+// Package lsp implements a language server of Jack over JSON-RPC, as defined by the Language Server Protocol.
+// All .jack files in the directory of a document are analyzed together on each request, as the compiler does.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+)
+
+// Server is a language server serving a client over a connection.
+type Server struct {
+	conn     *Conn
+	open     map[string][]byte // text of open documents by path
+	shutdown bool
+}
+
+func NewServer(r io.Reader, w io.Writer) *Server {
+	return &Server{
+		conn: NewConn(r, w),
+		open: make(map[string][]byte),
+	}
+}
+
+// Run serves until the exit notification. It returns an error if the client exits without shutdown or the connection fails.
+func (s *Server) Run() error {
+	for {
+		m, err := s.conn.Read()
+		if err != nil {
+			var re *ResponseError
+			if errors.As(err, &re) {
+				if err := s.conn.ReplyError(nil, re); err != nil {
+					return err
+				}
+				continue
+			}
+			return fmt.Errorf("[Server.Run] %w", err)
+		}
+
+		if m.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("[Server.Run] exit without shutdown")
+			}
+			return nil
+		}
+
+		result, err := s.handle(m)
+		if m.ID == nil {
+			// notifications have no response
+			continue
+		}
+		if err != nil {
+			var re *ResponseError
+			if !errors.As(err, &re) {
+				re = &ResponseError{Code: codeInvalidRequest, Message: err.Error()}
+			}
+			if err := s.conn.ReplyError(m.ID, re); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.conn.Reply(m.ID, result); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(m *message) (interface{}, error) {
+	if s.shutdown {
+		return nil, &ResponseError{Code: codeInvalidRequest, Message: "server is shut down"}
+	}
+
+	switch m.Method {
+	case "initialize":
+		return &InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:       syncFull,
+				DefinitionProvider:     true,
+				HoverProvider:          true,
+				CompletionProvider:     &CompletionOptions{TriggerCharacters: []string{"."}},
+				DocumentSymbolProvider: true,
+				ReferencesProvider:     true,
+			},
+			ServerInfo: ServerInfo{Name: "jack-lsp"},
+		}, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		path, err := uriToPath(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		s.open[path] = []byte(params.TextDocument.Text)
+		return nil, s.publishDiagnostics(filepath.Dir(path))
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		path, err := uriToPath(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			s.open[path] = []byte(params.ContentChanges[n-1].Text)
+		}
+		return nil, s.publishDiagnostics(filepath.Dir(path))
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		path, err := uriToPath(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		delete(s.open, path)
+		// clear the diagnostics in case the file is not saved
+		if err := s.conn.Notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}}); err != nil {
+			return nil, err
+		}
+		return nil, s.publishDiagnostics(filepath.Dir(path))
+	case "textDocument/didSave":
+		var params DidSaveTextDocumentParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		path, err := uriToPath(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return nil, s.publishDiagnostics(filepath.Dir(path))
+
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		p, d, offset, err := s.locate(params.TextDocument.URI, params.Position)
+		if err != nil || d == nil {
+			return nil, err
+		}
+		id := d.identAt(offset)
+		if id == nil || id.key == "" || p.decls[id.key] == nil {
+			return nil, nil
+		}
+		return location(p.decls[id.key]), nil
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		_, d, offset, err := s.locate(params.TextDocument.URI, params.Position)
+		if err != nil || d == nil {
+			return nil, err
+		}
+		id := d.identAt(offset)
+		if id == nil || id.hover == "" {
+			return nil, nil
+		}
+		return &Hover{
+			Contents: MarkupContent{Kind: "markdown", Value: id.hover},
+			Range:    d.rangeOf(id.node.Pos(), id.node.End()),
+		}, nil
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		p, d, offset, err := s.locate(params.TextDocument.URI, params.Position)
+		if err != nil || d == nil {
+			return nil, err
+		}
+		items := p.complete(d, offset)
+		if items == nil {
+			items = []CompletionItem{}
+		}
+		return items, nil
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		_, d, _, err := s.locate(params.TextDocument.URI, Position{})
+		if err != nil || d == nil {
+			return nil, err
+		}
+		return d.symbols(), nil
+	case "textDocument/references":
+		var params ReferenceParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		p, d, offset, err := s.locate(params.TextDocument.URI, params.Position)
+		if err != nil || d == nil {
+			return nil, err
+		}
+		res := []*Location{}
+		if id := d.identAt(offset); id != nil && id.key != "" {
+			for _, r := range p.references(id.key, params.Context.IncludeDeclaration) {
+				res = append(res, location(r))
+			}
+		}
+		return res, nil
+	}
+
+	if m.ID == nil {
+		// ignore unknown notifications such as $/cancelRequest
+		return nil, nil
+	}
+	return nil, &ResponseError{Code: codeMethodNotFound, Message: "method not found: " + m.Method}
+}
+
+// locate analyzes the project of the document and returns the document and the offset of the position.
+// The document is nil if it is not a .jack file.
+func (s *Server) locate(uri string, pos Position) (*project, *document, int, error) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	p, err := loadProject(filepath.Dir(path), s.open)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	d := p.doc(path)
+	if d == nil {
+		return p, nil, 0, nil
+	}
+	return p, d, offsetOf(d.src, pos), nil
+}
+
+// publishDiagnostics sends the diagnostics of all files in the directory, since a change may affect the other classes.
+func (s *Server) publishDiagnostics(dir string) error {
+	p, err := loadProject(dir, s.open)
+	if err != nil {
+		return err
+	}
+	for _, d := range p.docs {
+		diags := d.diags
+		if diags == nil {
+			diags = []Diagnostic{}
+		}
+		if err := s.conn.Notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{URI: pathToURI(d.path), Diagnostics: diags}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func location(id *ident) *Location {
+	return &Location{
+		URI:   pathToURI(id.doc.path),
+		Range: id.doc.rangeOf(id.node.Pos(), id.node.End()),
+	}
+}
+
+func unmarshalParams(m *message, v interface{}) error {
+	if err := json.Unmarshal(m.Params, v); err != nil {
+		return &ResponseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", &ResponseError{Code: codeInvalidParams, Message: "not a file uri: " + uri}
+	}
+	return filepath.Clean(filepath.FromSlash(u.Path)), nil
+}
+
+func pathToURI(path string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return u.String()
+}
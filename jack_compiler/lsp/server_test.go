@@ -0,0 +1,263 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testMain = `class Main {
+    function void main() {
+        var Point p;
+        let p = Point.new(1);
+        do p.move(2);
+        return;
+    }
+}
+`
+
+const testPoint = `class Point {
+    field int x;
+    constructor Point new(int ax) {
+        let x = ax;
+        return this;
+    }
+    method void move(int dx) {
+        let x = x + dx;
+        return;
+    }
+}
+`
+
+// session runs the server with the requests and returns the results by id and the published diagnostics by uri.
+func session(t *testing.T, requests []string) (map[int]json.RawMessage, map[string][]Diagnostic) {
+	t.Helper()
+	var in bytes.Buffer
+	for _, r := range requests {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(r), r)
+	}
+	var out bytes.Buffer
+	if err := NewServer(&in, &out).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	results := make(map[int]json.RawMessage)
+	diags := make(map[string][]Diagnostic)
+	conn := NewConn(&out, nil)
+	for {
+		m, err := conn.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Method == "textDocument/publishDiagnostics" {
+			var p PublishDiagnosticsParams
+			if err := json.Unmarshal(m.Params, &p); err != nil {
+				t.Fatal(err)
+			}
+			diags[p.URI] = p.Diagnostics
+			continue
+		}
+		var id int
+		if err := json.Unmarshal(*m.ID, &id); err != nil {
+			t.Fatal(err)
+		}
+		results[id] = m.Result
+	}
+	return results, diags
+}
+
+func TestServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jack-lsp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "Point.jack"), []byte(testPoint), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mainURI := pathToURI(filepath.Join(dir, "Main.jack"))
+	pointURI := pathToURI(filepath.Join(dir, "Point.jack"))
+	// Main.jack is only in the editor, with a syntax error
+	broken := testMain[:len(testMain)-len("    }\n}\n")] + "        do p.\n    }\n}\n"
+
+	text, _ := json.Marshal(testMain)
+	brokenText, _ := json.Marshal(broken)
+	pos := func(uri string, line, char int) string {
+		return fmt.Sprintf(`"textDocument": {"uri": %q}, "position": {"line": %d, "character": %d}`, uri, line, char)
+	}
+	results, diags := session(t, []string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {}}`,
+		`{"jsonrpc": "2.0", "method": "initialized", "params": {}}`,
+		fmt.Sprintf(`{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": {"textDocument": {"uri": %q, "version": 1, "text": %s}}}`, mainURI, text),
+		fmt.Sprintf(`{"jsonrpc": "2.0", "id": 2, "method": "textDocument/definition", "params": {%s}}`, pos(mainURI, 4, 14)),
+		fmt.Sprintf(`{"jsonrpc": "2.0", "id": 3, "method": "textDocument/hover", "params": {%s}}`, pos(mainURI, 4, 11)),
+		fmt.Sprintf(`{"jsonrpc": "2.0", "id": 4, "method": "textDocument/references", "params": {%s, "context": {"includeDeclaration": true}}}`, pos(pointURI, 1, 14)),
+		fmt.Sprintf(`{"jsonrpc": "2.0", "id": 5, "method": "textDocument/references", "params": {%s, "context": {"includeDeclaration": false}}}`, pos(mainURI, 3, 22)),
+		fmt.Sprintf(`{"jsonrpc": "2.0", "id": 6, "method": "textDocument/documentSymbol", "params": {"textDocument": {"uri": %q}}}`, pointURI),
+		fmt.Sprintf(`{"jsonrpc": "2.0", "method": "textDocument/didChange", "params": {"textDocument": {"uri": %q, "version": 2}, "contentChanges": [{"text": %s}]}}`, mainURI, brokenText),
+		fmt.Sprintf(`{"jsonrpc": "2.0", "id": 7, "method": "textDocument/completion", "params": {%s}}`, pos(mainURI, 6, 13)),
+		fmt.Sprintf(`{"jsonrpc": "2.0", "id": 8, "method": "textDocument/completion", "params": {%s}}`, pos(mainURI, 3, 22)),
+		`{"jsonrpc": "2.0", "id": 9, "method": "no/such/method", "params": {}}`,
+		`{"jsonrpc": "2.0", "id": 10, "method": "shutdown"}`,
+		`{"jsonrpc": "2.0", "method": "exit"}`,
+	})
+
+	rng := func(l1, c1, l2, c2 int) Range {
+		return Range{Start: Position{l1, c1}, End: Position{l2, c2}}
+	}
+	tests := []struct {
+		name string
+		id   int
+		got  interface{}
+		want interface{}
+	}{
+		{
+			name: "initialize",
+			id:   1,
+			got:  &InitializeResult{},
+			want: &InitializeResult{
+				Capabilities: ServerCapabilities{
+					TextDocumentSync:       syncFull,
+					DefinitionProvider:     true,
+					HoverProvider:          true,
+					CompletionProvider:     &CompletionOptions{TriggerCharacters: []string{"."}},
+					DocumentSymbolProvider: true,
+					ReferencesProvider:     true,
+				},
+				ServerInfo: ServerInfo{Name: "jack-lsp"},
+			},
+		},
+		{
+			name: "definition of method in other file",
+			id:   2,
+			got:  &Location{},
+			want: &Location{URI: pointURI, Range: rng(6, 16, 6, 20)},
+		},
+		{
+			name: "hover of local variable",
+			id:   3,
+			got:  &Hover{},
+			want: &Hover{
+				Contents: MarkupContent{Kind: "markdown", Value: "```jack\nvar Point p\n```\nkind: Var, type: Point, index: 0"},
+				Range:    rng(4, 11, 4, 12),
+			},
+		},
+		{
+			name: "references of field",
+			id:   4,
+			got:  &[]Location{},
+			want: &[]Location{
+				{URI: pointURI, Range: rng(1, 14, 1, 15)},
+				{URI: pointURI, Range: rng(3, 12, 3, 13)},
+				{URI: pointURI, Range: rng(7, 12, 7, 13)},
+				{URI: pointURI, Range: rng(7, 16, 7, 17)},
+			},
+		},
+		{
+			name: "references of constructor across files",
+			id:   5,
+			got:  &[]Location{},
+			want: &[]Location{
+				{URI: mainURI, Range: rng(3, 22, 3, 25)},
+			},
+		},
+		{
+			name: "document symbols",
+			id:   6,
+			got:  &[]DocumentSymbol{},
+			want: &[]DocumentSymbol{{
+				Name:           "Point",
+				Kind:           symbolClass,
+				Range:          rng(0, 0, 10, 1),
+				SelectionRange: rng(0, 6, 0, 11),
+				Children: []DocumentSymbol{
+					{Name: "x", Detail: "field int", Kind: symbolField, Range: rng(1, 4, 1, 16), SelectionRange: rng(1, 14, 1, 15)},
+					{Name: "new", Detail: "Point new(int)", Kind: symbolConstructor, Range: rng(2, 4, 5, 5), SelectionRange: rng(2, 22, 2, 25)},
+					{Name: "move", Detail: "void move(int)", Kind: symbolMethod, Range: rng(6, 4, 9, 5), SelectionRange: rng(6, 16, 6, 20)},
+				},
+			}},
+		},
+		{
+			name: "completion of methods of variable",
+			id:   7,
+			got:  &[]CompletionItem{},
+			want: &[]CompletionItem{
+				{Label: "move", Kind: completionMethod, Detail: "method void Point.move(int)"},
+			},
+		},
+		{
+			name: "completion of functions of class",
+			id:   8,
+			got:  &[]CompletionItem{},
+			want: &[]CompletionItem{
+				{Label: "new", Kind: completionConstructor, Detail: "constructor Point Point.new(int)"},
+			},
+		},
+		{
+			name: "shutdown",
+			id:   10,
+			got:  new(interface{}),
+			want: new(interface{}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, ok := results[tt.id]
+			if !ok {
+				t.Fatalf("no response of %d", tt.id)
+			}
+			if err := json.Unmarshal(res, tt.got); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.got, tt.want); diff != "" {
+				t.Errorf("result diff (-got +want)\n%s", diff)
+			}
+		})
+	}
+
+	if res := results[9]; res != nil {
+		t.Errorf("unknown method got result %s", res)
+	}
+	want := []Diagnostic{{Range: rng(7, 4, 7, 5), Severity: severityError, Source: "jack", Message: "expected subroutine name, found '}'"}}
+	if diff := cmp.Diff(diags[mainURI], want); diff != "" {
+		t.Errorf("diagnostics diff (-got +want)\n%s", diff)
+	}
+	if diff := cmp.Diff(diags[pointURI], []Diagnostic{}); diff != "" {
+		t.Errorf("diagnostics diff (-got +want)\n%s", diff)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	src := []byte("ab\n\"é𝄞\" x\r\nz")
+	tests := []struct {
+		offset int
+		want   Position
+	}{
+		{0, Position{0, 0}},
+		{2, Position{0, 2}},
+		{3, Position{1, 0}},
+		{6, Position{1, 2}},  // after é
+		{10, Position{1, 4}}, // after 𝄞, a surrogate pair
+		{13, Position{1, 7}},
+		{15, Position{2, 0}},
+	}
+	for _, tt := range tests {
+		got := position(src, tt.offset)
+		if diff := cmp.Diff(got, tt.want); diff != "" {
+			t.Errorf("position(%d) diff (-got +want)\n%s", tt.offset, diff)
+		}
+		if back := offsetOf(src, got); back != tt.offset {
+			t.Errorf("offsetOf(%v) = %d, want %d", got, back, tt.offset)
+		}
+	}
+}
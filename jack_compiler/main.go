@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -8,20 +10,39 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+
+	"github.com/cou929/nand2tetris/jack_compiler/jack"
 )
 
 var (
-	tokenize  = false
-	toStdout  = false
-	idAttr    = false
-	parseTree = false
+	tokenize     = false
+	toStdout     = false
+	parseTree    = false
+	check        = true
+	typecheck    = checkOff
+	flow         = checkOff
+	precedence   = precedenceOff
+	optimize     = false
+	shortCircuit = false
+	ext          = false
+	ast          astFlag
+	dot          dotFlag
 )
 
 func main() {
 	flag.BoolVar(&tokenize, "tokenize", false, "output tokenized result as xml")
 	flag.BoolVar(&toStdout, "toStdout", false, "output result to stdout instead of file")
-	flag.BoolVar(&idAttr, "idAttr", false, "output attributes of identifier node")
+	flag.BoolVar(&jack.IDAttr, "idAttr", false, "output attributes of identifier node")
 	flag.BoolVar(&parseTree, "parseTree", false, "output parse tree as xml format")
+	flag.BoolVar(&check, "check", true, "check references between classes before compiling")
+	flag.Var(&ast, "ast", "output parse tree with positions and symbol info as json or sexpr")
+	flag.Var(&dot, "dot", "output parse tree of each class or call graph of the program in Graphviz dot language (tree|callgraph)")
+	flag.Var(&typecheck, "typecheck", "check types and report problems as warnings, or errors with -typecheck=error")
+	flag.Var(&flow, "flow", "analyze control flow and report problems as warnings, or errors with -flow=error")
+	flag.Var(&precedence, "precedence", "apply * / before + - before < > = before & |, or warn where that differs from left to right with -precedence=warn")
+	flag.BoolVar(&optimize, "optimize", false, "fold constants and replace multiplications by powers of two with additions")
+	flag.BoolVar(&shortCircuit, "shortcircuit", false, "skip the right operand of & and | in if, while and for conditions if the left one decides the result, warning where that changes the behavior")
+	flag.BoolVar(&ext, "ext", false, "enable the language extensions: for, break, continue, else if, switch, const and enum")
 	flag.Parse()
 
 	if flag.NArg() < 1 {
@@ -33,17 +54,23 @@ func main() {
 		log.Fatal(err)
 	}
 
+	srcs := make(map[string][]byte)
+	trees := make(map[string]*jack.InnerNode)
+	failed := false
 	for _, f := range files {
-		reader, err := os.Open(f)
+		src, err := ioutil.ReadFile(f)
 		if err != nil {
 			log.Fatal(err, f)
 		}
-		defer reader.Close()
+		srcs[f] = src
 
-		tokenizer := NewTokenizer(reader)
+		tokenizer := jack.NewTokenizer(bytes.NewReader(src), f)
+		tokenizer.SetExtensions(ext)
 		tokens, err := tokenizer.Tokenize()
 		if err != nil {
-			log.Fatal(err, f)
+			reportSyntaxErrors(err, f, src)
+			failed = true
+			continue
 		}
 
 		if tokenize {
@@ -58,10 +85,13 @@ func main() {
 			continue
 		}
 
-		parser := NewParser()
+		parser := jack.NewParser()
+		parser.SetExtensions(ext)
 		tree, err := parser.Parse(tokens)
 		if err != nil {
-			log.Fatal(err, f)
+			reportSyntaxErrors(err, f, src)
+			failed = true
+			continue
 		}
 
 		if parseTree {
@@ -76,11 +106,72 @@ func main() {
 			continue
 		}
 
-		compiler := NewCompiler()
-		vmCode, err := compiler.Compile(tree)
+		if ast != "" {
+			out, err := astOutput(f, tree)
+			if err != nil {
+				log.Fatal(err, f)
+			}
+			if toStdout {
+				fmt.Println(out)
+				continue
+			}
+			if err := write(f, out, ".ast."+string(ast)); err != nil {
+				log.Fatal(err)
+			}
+			continue
+		}
+
+		if dot == "tree" {
+			out := jack.TreeDot(tree)
+			if toStdout {
+				fmt.Println(out)
+				continue
+			}
+			if err := write(f, out, ".dot"); err != nil {
+				log.Fatal(err)
+			}
+			continue
+		}
+
+		trees[f] = tree
+	}
+	if failed {
+		os.Exit(1)
+	}
+	if tokenize || parseTree || ast != "" || dot == "tree" {
+		return
+	}
+
+	prog, err := checkProgram(files, trees)
+	if err != nil {
+		var l jack.SemanticErrorList
+		if !errors.As(err, &l) {
+			log.Fatal(err)
+		}
+		for _, se := range l {
+			report(se, se.Snippet(srcs[se.Pos.File]))
+		}
+		if l.HasError() {
+			os.Exit(1)
+		}
+	}
+
+	graph := jack.NewCallGraph()
+	constants := prog.Constants()
+	for _, f := range files {
+		compiler := jack.NewCompiler()
+		compiler.SetCallGraph(graph)
+		compiler.SetPrecedence(precedence == precedenceOn)
+		compiler.SetOptimize(optimize)
+		compiler.SetShortCircuit(shortCircuit)
+		compiler.SetConstants(constants)
+		vmCode, err := compiler.Compile(trees[f])
 		if err != nil {
 			log.Fatal(err, f)
 		}
+		if dot == "callgraph" {
+			continue
+		}
 
 		if toStdout {
 			fmt.Println(vmCode)
@@ -90,6 +181,82 @@ func main() {
 			log.Fatal(err)
 		}
 	}
+
+	if dot == "callgraph" {
+		out := graph.Dot()
+		if toStdout {
+			fmt.Println(out)
+			return
+		}
+		if err := write(filepath.Join(dirPath, "callgraph.dot"), out, ".dot"); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// checkProgram checks the classes of all files together, as enabled by the flags.
+// It returns the program collected from the classes along with the problems found, sorted by position.
+func checkProgram(files []string, trees map[string]*jack.InnerNode) (*jack.Program, error) {
+	prog := jack.NewProgram()
+	prog.Precedence = precedence == precedenceOn
+	var errs jack.SemanticErrorList
+	for _, f := range files {
+		if err := prog.AddClass(trees[f]); err != nil {
+			errs = append(errs, err.(jack.SemanticErrorList)...)
+		}
+	}
+	for _, f := range files {
+		if check {
+			if err := prog.Check(trees[f]); err != nil {
+				errs = append(errs, err.(jack.SemanticErrorList)...)
+			}
+		}
+		if flow != checkOff {
+			if err := prog.FlowCheck(trees[f], flow == checkError); err != nil {
+				errs = append(errs, err.(jack.SemanticErrorList)...)
+			}
+		}
+		if typecheck != checkOff {
+			if err := prog.TypeCheck(trees[f], typecheck == checkError); err != nil {
+				errs = append(errs, err.(jack.SemanticErrorList)...)
+			}
+		}
+		if shortCircuit {
+			if err := prog.CheckShortCircuit(trees[f]); err != nil {
+				errs = append(errs, err.(jack.SemanticErrorList)...)
+			}
+		}
+		if precedence == precedenceWarn {
+			if err := jack.CheckPrecedence(trees[f]); err != nil {
+				errs = append(errs, err.(jack.SemanticErrorList)...)
+			}
+		}
+	}
+	errs.Sort()
+	return prog, errs.Err()
+}
+
+func astOutput(file string, tree jack.TreeNode) (string, error) {
+	a := jack.NewAST(file, tree)
+	if ast == "sexpr" {
+		return a.Sexpr(), nil
+	}
+	return a.JSON()
+}
+
+// reportSyntaxErrors reports the errors of the tokenizer or the parser in the file.
+func reportSyntaxErrors(err error, file string, src []byte) {
+	var l jack.SyntaxErrorList
+	if !errors.As(err, &l) {
+		log.Fatal(err, file)
+	}
+	for _, se := range l {
+		report(se, se.Snippet(src))
+	}
+}
+
+func report(err error, snippet string) {
+	fmt.Fprintf(os.Stderr, "%v\n%s\n", err, snippet)
 }
 
 func findJackFiles(dirPath string) ([]string, error) {
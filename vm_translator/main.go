@@ -1,28 +1,37 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path"
 	"strings"
+
+	"github.com/cou929/nand2tetris/vm_translator/translator"
 )
 
 func main() {
-	if len(os.Args) < 2 {
+	check := flag.Bool("check", false, "validate labels, calls and segment indices of the whole program before translation")
+	comment := flag.Bool("comment", false, "interleave the source VM command as a comment before each asm block")
+	sourceMap := flag.String("sourcemap", "", "write a JSON source map from ROM address to VM command into the file")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
 		log.Fatal("input dir required")
 	}
-	dirPath := os.Args[1]
+	dirPath := flag.Arg(0)
 
 	files, err := ioutil.ReadDir(dirPath)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	var codes []string
-	codes = BootstrapLine()
-
+	var errs translator.ErrorList
+	readers := map[string]io.Reader{}
 	for _, f := range files {
 		if f.IsDir() {
 			continue
@@ -34,22 +43,33 @@ func main() {
 		p := path.Join(dirPath, f.Name())
 		reader, err := os.Open(p)
 		if err != nil {
-			log.Fatal(err)
+			errs.Add(err)
+			continue
 		}
-		parser := NewParser(reader, f.Name())
-		commands, err := parser.Parse()
+		defer reader.Close()
+		readers[f.Name()] = reader
+	}
+
+	sm := &translator.SourceMap{}
+	codes, err := translator.Translate(readers, translator.Options{
+		Bootstrap: true,
+		Check:     *check,
+		Comment:   *comment,
+		SourceMap: sm,
+	})
+	errs.Add(err)
+	if len(errs) > 0 {
+		fmt.Fprintln(os.Stderr, errs)
+		os.Exit(1)
+	}
+
+	if *sourceMap != "" {
+		b, err := json.MarshalIndent(sm, "", "  ")
 		if err != nil {
 			log.Fatal(err)
 		}
-		for _, c := range commands {
-			asm, err := NewAsmCode(c)
-			if err != nil {
-				log.Fatal(err)
-			}
-			if asm == nil {
-				continue
-			}
-			codes = append(codes, asm.Code()...)
+		if err := ioutil.WriteFile(*sourceMap, b, 0644); err != nil {
+			log.Fatal(err)
 		}
 	}
 
@@ -1,4 +1,4 @@
-package main
+package translator
 
 import "fmt"
 
@@ -10,7 +10,7 @@ func (a AsmCode) Code() []string {
 	return a.line
 }
 
-func NewAsmCode(c *Command) (*AsmCode, error) {
+func NewAsmCode(c *Command, n *Namer) (*AsmCode, error) {
 	res := &AsmCode{}
 
 	if c.Type == CommandPush {
@@ -31,7 +31,7 @@ func NewAsmCode(c *Command) (*AsmCode, error) {
 		case "constant":
 			base = fmt.Sprintf("@%d", c.Arg2)
 		case "static":
-			base = fmt.Sprintf("@%s.%d", c.Meta.fileName, c.Arg2)
+			base = fmt.Sprintf("@%s", n.Static(c))
 		}
 
 		switch c.Arg1 {
@@ -109,7 +109,7 @@ func NewAsmCode(c *Command) (*AsmCode, error) {
 		case "temp":
 			base = fmt.Sprintf("@R5")
 		case "static":
-			base = fmt.Sprintf("@%s.%d", c.Meta.fileName, c.Arg2)
+			base = fmt.Sprintf("@%s", n.Static(c))
 		}
 		switch c.Arg1 {
 		case "static":
@@ -238,6 +238,7 @@ func NewAsmCode(c *Command) (*AsmCode, error) {
 			}
 			return res, nil
 		case "eq":
+			isZero, isNotZero, end := n.CompareLabels(c)
 			res.line = []string{
 				// pop y and set to D
 				"@SP",
@@ -251,23 +252,23 @@ func NewAsmCode(c *Command) (*AsmCode, error) {
 				"D=M-D",
 				"@SP",
 				"M=M-1",
-				fmt.Sprintf("@IS_ZERO.%s.%d", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("@%s", isZero),
 				"D;JEQ",
-				fmt.Sprintf("@IS_NOT_ZERO.%s.%d", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("@%s", isNotZero),
 				"0;JMP",
 				// comparison result to D
-				fmt.Sprintf("(IS_ZERO.%s.%d)", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("(%s)", isZero),
 				"@0",
 				"D=!A",
-				fmt.Sprintf("@END.%s.%d", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("@%s", end),
 				"0;JMP",
-				fmt.Sprintf("(IS_NOT_ZERO.%s.%d)", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("(%s)", isNotZero),
 				"@0",
 				"D=A",
-				fmt.Sprintf("@END.%s.%d", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("@%s", end),
 				"0;JMP",
 				// push added result
-				fmt.Sprintf("(END.%s.%d)", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("(%s)", end),
 				"@SP",
 				"A=M",
 				"M=D",
@@ -276,6 +277,7 @@ func NewAsmCode(c *Command) (*AsmCode, error) {
 			}
 			return res, nil
 		case "gt":
+			isZero, isNotZero, end := n.CompareLabels(c)
 			res.line = []string{
 				// pop y and set to D
 				"@SP",
@@ -289,23 +291,23 @@ func NewAsmCode(c *Command) (*AsmCode, error) {
 				"D=M-D",
 				"@SP",
 				"M=M-1",
-				fmt.Sprintf("@IS_ZERO.%s.%d", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("@%s", isZero),
 				"D;JGT",
-				fmt.Sprintf("@IS_NOT_ZERO.%s.%d", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("@%s", isNotZero),
 				"0;JMP",
 				// comparison result to D
-				fmt.Sprintf("(IS_ZERO.%s.%d)", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("(%s)", isZero),
 				"@0",
 				"D=!A",
-				fmt.Sprintf("@END.%s.%d", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("@%s", end),
 				"0;JMP",
-				fmt.Sprintf("(IS_NOT_ZERO.%s.%d)", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("(%s)", isNotZero),
 				"@0",
 				"D=A",
-				fmt.Sprintf("@END.%s.%d", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("@%s", end),
 				"0;JMP",
 				// push added result
-				fmt.Sprintf("(END.%s.%d)", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("(%s)", end),
 				"@SP",
 				"A=M",
 				"M=D",
@@ -314,6 +316,7 @@ func NewAsmCode(c *Command) (*AsmCode, error) {
 			}
 			return res, nil
 		case "lt":
+			isZero, isNotZero, end := n.CompareLabels(c)
 			res.line = []string{
 				// pop y and set to D
 				"@SP",
@@ -327,23 +330,23 @@ func NewAsmCode(c *Command) (*AsmCode, error) {
 				"D=M-D",
 				"@SP",
 				"M=M-1",
-				fmt.Sprintf("@IS_ZERO.%s.%d", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("@%s", isZero),
 				"D;JLT",
-				fmt.Sprintf("@IS_NOT_ZERO.%s.%d", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("@%s", isNotZero),
 				"0;JMP",
 				// comparison result to D
-				fmt.Sprintf("(IS_ZERO.%s.%d)", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("(%s)", isZero),
 				"@0",
 				"D=!A",
-				fmt.Sprintf("@END.%s.%d", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("@%s", end),
 				"0;JMP",
-				fmt.Sprintf("(IS_NOT_ZERO.%s.%d)", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("(%s)", isNotZero),
 				"@0",
 				"D=A",
-				fmt.Sprintf("@END.%s.%d", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("@%s", end),
 				"0;JMP",
 				// push added result
-				fmt.Sprintf("(END.%s.%d)", c.Meta.fileName, c.Meta.lineNum),
+				fmt.Sprintf("(%s)", end),
 				"@SP",
 				"A=M",
 				"M=D",
@@ -416,14 +419,14 @@ func NewAsmCode(c *Command) (*AsmCode, error) {
 
 	if c.Type == CommandLabel {
 		res.line = []string{
-			fmt.Sprintf("(%s$%s)", c.Meta.funcName, c.Arg1),
+			fmt.Sprintf("(%s)", n.Label(c)),
 		}
 		return res, nil
 	}
 
 	if c.Type == CommandGoto {
 		res.line = []string{
-			fmt.Sprintf("@%s$%s", c.Meta.funcName, c.Arg1),
+			fmt.Sprintf("@%s", n.Label(c)),
 			"0;JMP",
 		}
 		return res, nil
@@ -436,7 +439,7 @@ func NewAsmCode(c *Command) (*AsmCode, error) {
 			"D=M",
 			"@SP",
 			"M=M-1",
-			fmt.Sprintf("@%s$%s", c.Meta.funcName, c.Arg1),
+			fmt.Sprintf("@%s", n.Label(c)),
 			"D;JNE",
 		}
 		return res, nil
@@ -530,7 +533,7 @@ func NewAsmCode(c *Command) (*AsmCode, error) {
 	}
 
 	if c.Type == CommandCall {
-		retAddr := fmt.Sprintf("Return:%s.%s.%d", c.Meta.fileName, c.Meta.funcName, c.Meta.lineNum)
+		retAddr := n.ReturnLabel(c)
 		res.line = []string{
 			// hold return address
 			fmt.Sprintf("@%s", retAddr),
@@ -598,7 +601,9 @@ func NewAsmCode(c *Command) (*AsmCode, error) {
 	return nil, nil
 }
 
-func BootstrapLine() []string {
+// BootstrapLine returns the code initializing SP and calling Sys.init, named by the namer.
+func BootstrapLine(namer *Namer) []string {
+	ret := namer.BootstrapReturnLabel()
 	return []string{
 		// initialize SP
 		"@256",
@@ -607,7 +612,7 @@ func BootstrapLine() []string {
 		"M=D",
 		// call Sys.init
 		// hold return address
-		"@Return:vm:bootstrap",
+		"@" + ret,
 		"D=A",
 		"@SP",
 		"A=M",
@@ -664,6 +669,6 @@ func BootstrapLine() []string {
 		"@Sys.init",
 		"0;JMP",
 		// mark return address
-		"(Return:vm:bootstrap)",
+		"(" + ret + ")",
 	}
 }
@@ -1,4 +1,4 @@
-package main
+package translator
 
 import (
 	"reflect"
@@ -238,7 +238,7 @@ func TestNewAsmCode_Push(t *testing.T) {
 			},
 			want: &AsmCode{
 				line: []string{
-					"@TestClass.vm.99",
+					"@TestClass.99",
 					"D=M",
 					"@SP",
 					"A=M",
@@ -252,7 +252,7 @@ func TestNewAsmCode_Push(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewAsmCode(tt.args.c)
+			got, err := NewAsmCode(tt.args.c, NewNamer())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewAsmCode() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -524,7 +524,7 @@ func TestNewAsmCode_Pop(t *testing.T) {
 			want: &AsmCode{
 				line: []string{
 					// address to set
-					"@TestClass.vm.99",
+					"@TestClass.99",
 					"D=A",
 					"@POP_DEST",
 					"M=D",
@@ -544,7 +544,7 @@ func TestNewAsmCode_Pop(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewAsmCode(tt.args.c)
+			got, err := NewAsmCode(tt.args.c, NewNamer())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewAsmCode() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -698,23 +698,23 @@ func TestNewAsmCode_Arithmetic(t *testing.T) {
 					"D=M-D",
 					"@SP",
 					"M=M-1",
-					"@IS_ZERO.TestClass.vm.2",
+					"@TestClass.fooFn$IS_ZERO.0",
 					"D;JEQ",
-					"@IS_NOT_ZERO.TestClass.vm.2",
+					"@TestClass.fooFn$IS_NOT_ZERO.0",
 					"0;JMP",
 					// comparison result to D
-					"(IS_ZERO.TestClass.vm.2)",
+					"(TestClass.fooFn$IS_ZERO.0)",
 					"@0",
 					"D=!A",
-					"@END.TestClass.vm.2",
+					"@TestClass.fooFn$CMP_END.0",
 					"0;JMP",
-					"(IS_NOT_ZERO.TestClass.vm.2)",
+					"(TestClass.fooFn$IS_NOT_ZERO.0)",
 					"@0",
 					"D=A",
-					"@END.TestClass.vm.2",
+					"@TestClass.fooFn$CMP_END.0",
 					"0;JMP",
 					// push added result
-					"(END.TestClass.vm.2)",
+					"(TestClass.fooFn$CMP_END.0)",
 					"@SP",
 					"A=M",
 					"M=D",
@@ -751,23 +751,23 @@ func TestNewAsmCode_Arithmetic(t *testing.T) {
 					"D=M-D",
 					"@SP",
 					"M=M-1",
-					"@IS_ZERO.TestClass.vm.2",
+					"@TestClass.fooFn$IS_ZERO.0",
 					"D;JGT",
-					"@IS_NOT_ZERO.TestClass.vm.2",
+					"@TestClass.fooFn$IS_NOT_ZERO.0",
 					"0;JMP",
 					// comparison result to D
-					"(IS_ZERO.TestClass.vm.2)",
+					"(TestClass.fooFn$IS_ZERO.0)",
 					"@0",
 					"D=!A",
-					"@END.TestClass.vm.2",
+					"@TestClass.fooFn$CMP_END.0",
 					"0;JMP",
-					"(IS_NOT_ZERO.TestClass.vm.2)",
+					"(TestClass.fooFn$IS_NOT_ZERO.0)",
 					"@0",
 					"D=A",
-					"@END.TestClass.vm.2",
+					"@TestClass.fooFn$CMP_END.0",
 					"0;JMP",
 					// push added result
-					"(END.TestClass.vm.2)",
+					"(TestClass.fooFn$CMP_END.0)",
 					"@SP",
 					"A=M",
 					"M=D",
@@ -804,23 +804,23 @@ func TestNewAsmCode_Arithmetic(t *testing.T) {
 					"D=M-D",
 					"@SP",
 					"M=M-1",
-					"@IS_ZERO.TestClass.vm.2",
+					"@TestClass.fooFn$IS_ZERO.0",
 					"D;JLT",
-					"@IS_NOT_ZERO.TestClass.vm.2",
+					"@TestClass.fooFn$IS_NOT_ZERO.0",
 					"0;JMP",
 					// comparison result to D
-					"(IS_ZERO.TestClass.vm.2)",
+					"(TestClass.fooFn$IS_ZERO.0)",
 					"@0",
 					"D=!A",
-					"@END.TestClass.vm.2",
+					"@TestClass.fooFn$CMP_END.0",
 					"0;JMP",
-					"(IS_NOT_ZERO.TestClass.vm.2)",
+					"(TestClass.fooFn$IS_NOT_ZERO.0)",
 					"@0",
 					"D=A",
-					"@END.TestClass.vm.2",
+					"@TestClass.fooFn$CMP_END.0",
 					"0;JMP",
 					// push added result
-					"(END.TestClass.vm.2)",
+					"(TestClass.fooFn$CMP_END.0)",
 					"@SP",
 					"A=M",
 					"M=D",
@@ -938,7 +938,7 @@ func TestNewAsmCode_Arithmetic(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewAsmCode(tt.args.c)
+			got, err := NewAsmCode(tt.args.c, NewNamer())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewAsmCode() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -983,7 +983,7 @@ func TestNewAsmCode_Label(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewAsmCode(tt.args.c)
+			got, err := NewAsmCode(tt.args.c, NewNamer())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewAsmCode() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1029,7 +1029,7 @@ func TestNewAsmCode_Goto(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewAsmCode(tt.args.c)
+			got, err := NewAsmCode(tt.args.c, NewNamer())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewAsmCode() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1080,7 +1080,7 @@ func TestNewAsmCode_If(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewAsmCode(tt.args.c)
+			got, err := NewAsmCode(tt.args.c, NewNamer())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewAsmCode() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1147,7 +1147,7 @@ func TestNewAsmCode_Function(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewAsmCode(tt.args.c)
+			got, err := NewAsmCode(tt.args.c, NewNamer())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewAsmCode() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1252,7 +1252,7 @@ func TestNewAsmCode_Return(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewAsmCode(tt.args.c)
+			got, err := NewAsmCode(tt.args.c, NewNamer())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewAsmCode() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1291,7 +1291,7 @@ func TestNewAsmCode_Call(t *testing.T) {
 			want: &AsmCode{
 				line: []string{
 					// hold return address
-					"@Return:TestClass.vm.TestClass.fooFn.2",
+					"@TestClass.fooFn$ret.0",
 					"D=A",
 					"@SP",
 					"A=M",
@@ -1348,7 +1348,7 @@ func TestNewAsmCode_Call(t *testing.T) {
 					"@myFunc",
 					"0;JMP",
 					// mark return address
-					"(Return:TestClass.vm.TestClass.fooFn.2)",
+					"(TestClass.fooFn$ret.0)",
 				},
 			},
 			wantErr: false,
@@ -1356,7 +1356,7 @@ func TestNewAsmCode_Call(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewAsmCode(tt.args.c)
+			got, err := NewAsmCode(tt.args.c, NewNamer())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewAsmCode() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1383,7 +1383,7 @@ func TestBootstrapLine(t *testing.T) {
 				"M=D",
 				// call Sys.init
 				// hold return address
-				"@Return:vm:bootstrap",
+				"@Bootstrap$ret.0",
 				"D=A",
 				"@SP",
 				"A=M",
@@ -1440,13 +1440,13 @@ func TestBootstrapLine(t *testing.T) {
 				"@Sys.init",
 				"0;JMP",
 				// mark return address
-				"(Return:vm:bootstrap)",
+				"(Bootstrap$ret.0)",
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := BootstrapLine(); !reflect.DeepEqual(got, tt.want) {
+			if got := BootstrapLine(NewNamer()); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("BootstrapLine() = %v, want %v", got, tt.want)
 			}
 		})
@@ -0,0 +1,188 @@
+package translator
+
+import (
+	"fmt"
+	"sort"
+)
+
+// osFunctions is the arity of each Jack OS function, used when the program does not define them itself.
+var osFunctions = map[string]int{
+	"Math.init":     0,
+	"Math.abs":      1,
+	"Math.multiply": 2,
+	"Math.divide":   2,
+	"Math.min":      2,
+	"Math.max":      2,
+	"Math.sqrt":     1,
+
+	"String.new":           1,
+	"String.dispose":       1,
+	"String.length":        1,
+	"String.charAt":        2,
+	"String.setCharAt":     3,
+	"String.appendChar":    2,
+	"String.eraseLastChar": 1,
+	"String.intValue":      1,
+	"String.setInt":        2,
+	"String.backSpace":     0,
+	"String.doubleQuote":   0,
+	"String.newLine":       0,
+
+	"Array.new":     1,
+	"Array.dispose": 1,
+
+	"Output.init":        0,
+	"Output.moveCursor":  2,
+	"Output.printChar":   1,
+	"Output.printString": 1,
+	"Output.printInt":    1,
+	"Output.println":     0,
+	"Output.backSpace":   0,
+
+	"Screen.init":          0,
+	"Screen.clearScreen":   0,
+	"Screen.setColor":      1,
+	"Screen.drawPixel":     2,
+	"Screen.drawLine":      4,
+	"Screen.drawRectangle": 4,
+	"Screen.drawCircle":    3,
+
+	"Keyboard.init":       0,
+	"Keyboard.keyPressed": 0,
+	"Keyboard.readChar":   0,
+	"Keyboard.readLine":   1,
+	"Keyboard.readInt":    1,
+
+	"Memory.init":    0,
+	"Memory.peek":    1,
+	"Memory.poke":    2,
+	"Memory.alloc":   1,
+	"Memory.deAlloc": 1,
+
+	"Sys.init":  0,
+	"Sys.halt":  0,
+	"Sys.error": 1,
+	"Sys.wait":  1,
+}
+
+// segmentSize is the number of valid indices of each fixed size memory segment.
+var segmentSize = map[CommandArg1]int{
+	"constant": 32768,
+	"static":   240,
+	"pointer":  2,
+	"temp":     8,
+}
+
+type callSite struct {
+	cmd  *Command
+	args int
+}
+
+// Check validates the parsed commands of the whole program (all files) and returns every problem as an ErrorList.
+func Check(commands []*Command) error {
+	var errs ErrorList
+
+	// labels outside of functions are allowed only in files without functions, such as the ProgramFlow tests,
+	// and are scoped by the file as the translator does
+	hasFuncs := map[string]bool{}
+	for _, c := range commands {
+		if c.Type == CommandFunction {
+			hasFuncs[c.Meta.fileName] = true
+		}
+	}
+
+	funcs := map[string]*Command{}
+	labels := map[string]map[CommandArg1]*Command{}
+	for _, c := range commands {
+		switch c.Type {
+		case CommandFunction:
+			name := string(c.Arg1)
+			if prev, ok := funcs[name]; ok {
+				errs.Add(newCheckError(c, "Duplicate function %s, already defined at %s:%d", name, prev.Meta.fileName, prev.Meta.srcLine))
+				continue
+			}
+			funcs[name] = c
+		case CommandLabel:
+			if c.Meta.funcName == "" && hasFuncs[c.Meta.fileName] {
+				errs.Add(newCheckError(c, "Label %s outside of function", c.Arg1))
+				continue
+			}
+			s := scope(c)
+			if labels[s] == nil {
+				labels[s] = map[CommandArg1]*Command{}
+			}
+			if prev, ok := labels[s][c.Arg1]; ok {
+				errs.Add(newCheckError(c, "Duplicate label %s in %s, already defined at line %d", c.Arg1, s, prev.Meta.srcLine))
+				continue
+			}
+			labels[s][c.Arg1] = c
+		}
+	}
+
+	calls := map[string]callSite{}
+	for _, c := range commands {
+		switch c.Type {
+		case CommandPush, CommandPop:
+			if c.Arg2 < 0 {
+				errs.Add(newCheckError(c, "Negative index %d of %s segment", c.Arg2, c.Arg1))
+				continue
+			}
+			if size, ok := segmentSize[c.Arg1]; ok && int(c.Arg2) >= size {
+				errs.Add(newCheckError(c, "Index %d out of range of %s segment (0-%d)", c.Arg2, c.Arg1, size-1))
+			}
+		case CommandGoto, CommandIf:
+			if c.Meta.funcName == "" && hasFuncs[c.Meta.fileName] {
+				errs.Add(newCheckError(c, "Jump to %s outside of function", c.Arg1))
+				continue
+			}
+			if _, ok := labels[scope(c)][c.Arg1]; !ok {
+				errs.Add(newCheckError(c, "Undefined label %s in %s", c.Arg1, scope(c)))
+			}
+		case CommandFunction:
+			if c.Arg2 < 0 {
+				errs.Add(newCheckError(c, "Negative number of locals %d", c.Arg2))
+			}
+		case CommandCall:
+			name := string(c.Arg1)
+			if c.Arg2 < 0 {
+				errs.Add(newCheckError(c, "Negative number of arguments %d", c.Arg2))
+				continue
+			}
+			_, defined := funcs[name]
+			arity, isOS := osFunctions[name]
+			if !defined && !isOS {
+				errs.Add(newCheckError(c, "Call to undefined function %s", name))
+				continue
+			}
+			if !defined && int(c.Arg2) != arity {
+				errs.Add(newCheckError(c, "Call to %s with %d arguments, want %d", name, c.Arg2, arity))
+				continue
+			}
+			if prev, ok := calls[name]; ok {
+				if prev.args != int(c.Arg2) {
+					errs.Add(newCheckError(c, "Call to %s with %d arguments, but called with %d at %s:%d", name, c.Arg2, prev.args, prev.cmd.Meta.fileName, prev.cmd.Meta.srcLine))
+				}
+				continue
+			}
+			calls[name] = callSite{cmd: c, args: int(c.Arg2)}
+		}
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		a, b := errs[i].(*SourceError), errs[j].(*SourceError)
+		if a.FileName != b.FileName {
+			return a.FileName < b.FileName
+		}
+		return a.Line < b.Line
+	})
+
+	return errs.Err()
+}
+
+func newCheckError(c *Command, format string, a ...interface{}) error {
+	return &SourceError{
+		FileName: c.Meta.fileName,
+		Line:     c.Meta.srcLine,
+		Err:      fmt.Errorf(format, a...),
+	}
+}
@@ -0,0 +1,172 @@
+package translator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseFiles(t *testing.T, files map[string][]string, order []string) []*Command {
+	t.Helper()
+	var res []*Command
+	for _, n := range order {
+		p := NewParser(strings.NewReader(strings.Join(files[n], "\n")), n)
+		cmds, err := p.Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, cmds...)
+	}
+	return res
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string][]string
+		order []string
+		want  []string
+	}{
+		{
+			name: "valid program",
+			files: map[string][]string{
+				"Main.vm": {
+					"function Main.main 1",
+					"push constant 32767",
+					"pop temp 7",
+					"push pointer 1",
+					"label LOOP",
+					"call Main.sub 1",
+					"if-goto LOOP",
+					"call Main.sub 1",
+					"call Output.printInt 1",
+					"return",
+					"function Main.sub 0",
+					"label LOOP",
+					"goto LOOP",
+				},
+			},
+			order: []string{"Main.vm"},
+			want:  nil,
+		},
+		{
+			name: "segment index out of range",
+			files: map[string][]string{
+				"Main.vm": {
+					"function Main.main 0",
+					"push temp 9",
+					"pop pointer 2",
+					"push constant 32768",
+					"push static 240",
+					"push local 300",
+				},
+			},
+			order: []string{"Main.vm"},
+			want: []string{
+				"Main.vm:2: Index 9 out of range of temp segment (0-7)",
+				"Main.vm:3: Index 2 out of range of pointer segment (0-1)",
+				"Main.vm:4: Index 32768 out of range of constant segment (0-32767)",
+				"Main.vm:5: Index 240 out of range of static segment (0-239)",
+			},
+		},
+		{
+			name: "labels",
+			files: map[string][]string{
+				"Main.vm": {
+					"label TOP",
+					"goto TOP",
+					"function Main.main 0",
+					"label L1",
+					"label L1",
+					"goto L2",
+					"function Main.sub 0",
+					"if-goto L1",
+				},
+			},
+			order: []string{"Main.vm"},
+			want: []string{
+				"Main.vm:1: Label TOP outside of function",
+				"Main.vm:2: Jump to TOP outside of function",
+				"Main.vm:5: Duplicate label L1 in Main.main, already defined at line 4",
+				"Main.vm:6: Undefined label L2 in Main.main",
+				"Main.vm:8: Undefined label L1 in Main.sub",
+			},
+		},
+		{
+			name: "labels in files without functions",
+			files: map[string][]string{
+				"Loop.vm": {
+					"label LOOP",
+					"if-goto LOOP",
+					"label LOOP",
+					"goto END",
+				},
+				"Main.vm": {
+					"function Main.main 0",
+					"label END",
+				},
+			},
+			order: []string{"Loop.vm", "Main.vm"},
+			want: []string{
+				"Loop.vm:3: Duplicate label LOOP in Loop, already defined at line 1",
+				"Loop.vm:4: Undefined label END in Loop",
+			},
+		},
+		{
+			name: "functions and calls",
+			files: map[string][]string{
+				"Foo.vm": {
+					"function Foo.bar 0",
+					"return",
+				},
+				"Main.vm": {
+					"function Main.main 0",
+					"call Foo.bar 1",
+					"call Foo.bar 2",
+					"call Foo.baz 0",
+					"call Math.multiply 1",
+					"return",
+					"function Foo.bar 0",
+					"return",
+				},
+			},
+			order: []string{"Foo.vm", "Main.vm"},
+			want: []string{
+				"Main.vm:3: Call to Foo.bar with 2 arguments, but called with 1 at Main.vm:2",
+				"Main.vm:4: Call to undefined function Foo.baz",
+				"Main.vm:5: Call to Math.multiply with 1 arguments, want 2",
+				"Main.vm:7: Duplicate function Foo.bar, already defined at Foo.vm:1",
+			},
+		},
+		{
+			name: "OS functions defined by the program",
+			files: map[string][]string{
+				"Math.vm": {
+					"function Math.multiply 2",
+					"return",
+				},
+				"Main.vm": {
+					"function Main.main 0",
+					"call Math.multiply 3",
+					"return",
+				},
+			},
+			order: []string{"Main.vm", "Math.vm"},
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(parseFiles(t, tt.files, tt.order))
+			var got []string
+			if err != nil {
+				for _, e := range err.(ErrorList) {
+					got = append(got, e.Error())
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package translator
 
 import (
 	"fmt"
@@ -27,7 +27,7 @@ type CommandArg2 int
 type CommandMeta struct {
 	fileName string
 	funcName string
-	lineNum  int
+	srcLine  int
 }
 
 type Command struct {
@@ -198,10 +198,34 @@ func (c *Command) setArg2(arg int) error {
 	return nil
 }
 
-func (c *Command) SetMeta(fileName string, funcName string, lineNum int) {
+func (c *Command) SetMeta(fileName string, funcName string, srcLine int) {
 	c.Meta = &CommandMeta{
 		fileName: fileName,
 		funcName: funcName,
-		lineNum:  lineNum,
+		srcLine:  srcLine,
 	}
 }
+
+func (c *Command) String() string {
+	switch c.Type {
+	case CommandArithmetic:
+		return string(c.Arg1)
+	case CommandPush:
+		return fmt.Sprintf("push %s %d", c.Arg1, c.Arg2)
+	case CommandPop:
+		return fmt.Sprintf("pop %s %d", c.Arg1, c.Arg2)
+	case CommandLabel:
+		return fmt.Sprintf("label %s", c.Arg1)
+	case CommandGoto:
+		return fmt.Sprintf("goto %s", c.Arg1)
+	case CommandIf:
+		return fmt.Sprintf("if-goto %s", c.Arg1)
+	case CommandFunction:
+		return fmt.Sprintf("function %s %d", c.Arg1, c.Arg2)
+	case CommandCall:
+		return fmt.Sprintf("call %s %d", c.Arg1, c.Arg2)
+	case CommandReturn:
+		return "return"
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SourceError is an error tied to a line of a .vm source file.
+type SourceError struct {
+	FileName string
+	Line     int
+	Err      error
+}
+
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.FileName, e.Line, e.Err)
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorList collects errors so that all of them can be reported at once.
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	var msgs []string
+	for _, e := range l {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Add appends err, flattening it when it is an ErrorList itself.
+func (l *ErrorList) Add(err error) {
+	if err == nil {
+		return
+	}
+	if el, ok := err.(ErrorList); ok {
+		*l = append(*l, el...)
+		return
+	}
+	*l = append(*l, err)
+}
+
+// Err returns nil when the list is empty so that callers can use the usual `err != nil` check.
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
@@ -0,0 +1,70 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Namer generates assembly symbols following the naming convention of the book:
+// `File.i` for statics, `func$label` for labels and `func$ret.k` for return addresses.
+// Counters are kept per function, so a Namer must be shared among the commands of a file.
+type Namer struct {
+	retCount map[string]int
+	cmpCount map[string]int
+}
+
+func NewNamer() *Namer {
+	return &Namer{
+		retCount: map[string]int{},
+		cmpCount: map[string]int{},
+	}
+}
+
+// Static returns the symbol of `static i` in the file, e.g. `Main.3` for `Main.vm`.
+func (n *Namer) Static(c *Command) string {
+	return fmt.Sprintf("%s.%d", fileBase(c.Meta.fileName), c.Arg2)
+}
+
+// Label returns the symbol of the label of label, goto and if-goto commands.
+func (n *Namer) Label(c *Command) string {
+	return fmt.Sprintf("%s$%s", scope(c), c.Arg1)
+}
+
+// ReturnLabel returns a new return address symbol of the call command.
+func (n *Namer) ReturnLabel(c *Command) string {
+	return n.returnLabel(scope(c))
+}
+
+// BootstrapReturnLabel returns a new return address symbol of the call to Sys.init in the bootstrap code,
+// which is outside of any function and file.
+func (n *Namer) BootstrapReturnLabel() string {
+	return n.returnLabel("Bootstrap")
+}
+
+func (n *Namer) returnLabel(s string) string {
+	k := n.retCount[s]
+	n.retCount[s]++
+	return fmt.Sprintf("%s$ret.%d", s, k)
+}
+
+// CompareLabels returns new symbols for the true branch, false branch and end of the comparison command.
+func (n *Namer) CompareLabels(c *Command) (string, string, string) {
+	s := scope(c)
+	k := n.cmpCount[s]
+	n.cmpCount[s]++
+	return fmt.Sprintf("%s$IS_ZERO.%d", s, k),
+		fmt.Sprintf("%s$IS_NOT_ZERO.%d", s, k),
+		fmt.Sprintf("%s$CMP_END.%d", s, k)
+}
+
+// scope is the enclosing function, or the file name for commands outside of functions.
+func scope(c *Command) string {
+	if c.Meta.funcName != "" {
+		return c.Meta.funcName
+	}
+	return fileBase(c.Meta.fileName)
+}
+
+func fileBase(fileName string) string {
+	return strings.TrimSuffix(fileName, ".vm")
+}
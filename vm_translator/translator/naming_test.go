@@ -0,0 +1,39 @@
+package translator
+
+import "testing"
+
+func TestNamer(t *testing.T) {
+	n := NewNamer()
+	inFunc := &Command{Type: CommandCall, Arg1: "Foo.bar", Arg2: 3, Meta: &CommandMeta{"Main.vm", "Main.main", 1}}
+	other := &Command{Type: CommandCall, Arg1: "Foo.bar", Arg2: 3, Meta: &CommandMeta{"Main.vm", "Main.sub", 1}}
+	global := &Command{Type: CommandLabel, Arg1: "LOOP", Meta: &CommandMeta{"BasicLoop.vm", "", 1}}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "static", got: n.Static(inFunc), want: "Main.3"},
+		{name: "label", got: n.Label(&Command{Type: CommandLabel, Arg1: "LOOP", Meta: inFunc.Meta}), want: "Main.main$LOOP"},
+		{name: "label outside of function", got: n.Label(global), want: "BasicLoop$LOOP"},
+		{name: "first return label", got: n.ReturnLabel(inFunc), want: "Main.main$ret.0"},
+		{name: "second return label", got: n.ReturnLabel(inFunc), want: "Main.main$ret.1"},
+		{name: "counter per function", got: n.ReturnLabel(other), want: "Main.sub$ret.0"},
+		{name: "bootstrap return label", got: n.BootstrapReturnLabel(), want: "Bootstrap$ret.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("Namer = %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+
+	isZero, isNotZero, end := n.CompareLabels(inFunc)
+	if isZero != "Main.main$IS_ZERO.0" || isNotZero != "Main.main$IS_NOT_ZERO.0" || end != "Main.main$CMP_END.0" {
+		t.Errorf("Namer.CompareLabels() = %v, %v, %v", isZero, isNotZero, end)
+	}
+	if isZero, _, _ := n.CompareLabels(inFunc); isZero != "Main.main$IS_ZERO.1" {
+		t.Errorf("Namer.CompareLabels() = %v, want Main.main$IS_ZERO.1", isZero)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package translator
 
 import (
 	"bufio"
@@ -14,7 +14,7 @@ type Parser struct {
 	reader   io.Reader
 	fileName string
 	curFunc  string
-	curLine  int
+	srcLine  int
 }
 
 func NewParser(reader io.Reader, n string) *Parser {
@@ -24,25 +24,28 @@ func NewParser(reader io.Reader, n string) *Parser {
 	}
 }
 
+// Parse continues on invalid lines and returns all of their errors together as an ErrorList.
 func (p *Parser) Parse() ([]*Command, error) {
 	var res []*Command
+	var errs ErrorList
 
 	scanner := bufio.NewScanner(p.reader)
 	for scanner.Scan() {
 		l := scanner.Text()
+		p.srcLine++
 		c, err := p.parseLine(l)
 		if err != nil {
-			return nil, err
+			errs.Add(&SourceError{FileName: p.fileName, Line: p.srcLine, Err: err})
+			continue
 		}
 		if c == nil {
 			continue
 		}
 
-		p.curLine++
 		if c.Type == CommandFunction {
 			p.curFunc = string(c.Arg1)
 		}
-		c.SetMeta(p.fileName, p.curFunc, p.curLine)
+		c.SetMeta(p.fileName, p.curFunc, p.srcLine)
 
 		res = append(res, c)
 	}
@@ -51,7 +54,7 @@ func (p *Parser) Parse() ([]*Command, error) {
 		return nil, err
 	}
 
-	return res, nil
+	return res, errs.Err()
 }
 
 func (p *Parser) parseLine(line string) (*Command, error) {
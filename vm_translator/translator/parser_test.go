@@ -1,7 +1,8 @@
-package main
+package translator
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -81,3 +82,40 @@ func TestParser_parseLine(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_Parse_errors(t *testing.T) {
+	src := strings.Join([]string{
+		"// comment",
+		"function Main.main 0",
+		"push local",
+		"",
+		"push constant 1",
+		"mult",
+		"return",
+	}, "\n")
+
+	p := NewParser(strings.NewReader(src), "Main.vm")
+	got, err := p.Parse()
+	if len(got) != 3 {
+		t.Errorf("Parser.Parse() returned %d commands, want 3", len(got))
+	}
+	errs, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("Parser.Parse() error = %v, want ErrorList", err)
+	}
+	want := []string{
+		"Main.vm:3: Invalid number of tokens [push local]",
+		"Main.vm:6: Invalid arithmetic command mult",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Parser.Parse() errors = %v, want %v", errs, want)
+	}
+	for i, e := range errs {
+		if e.Error() != want[i] {
+			t.Errorf("Parser.Parse() errors[%d] = %v, want %v", i, e, want[i])
+		}
+	}
+	if got[2].Meta.srcLine != 7 {
+		t.Errorf("Parser.Parse() srcLine = %d, want 7", got[2].Meta.srcLine)
+	}
+}
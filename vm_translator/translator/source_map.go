@@ -0,0 +1,90 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SourceMapEntry maps the ROM addresses [Start, End) of the generated assembly to the VM command they came from.
+type SourceMapEntry struct {
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+	Command  string `json:"command"`
+}
+
+// SourceMap maps the instruction index (ROM address after assembling) to the VM source.
+type SourceMap struct {
+	Entries []*SourceMapEntry `json:"entries"`
+	addr    int
+}
+
+// Skip advances the address by code not originating from any VM command, like bootstrap code.
+func (s *SourceMap) Skip(code []string) {
+	s.addr += countInstructions(code)
+}
+
+func (s *SourceMap) Add(c *Command, code []string) {
+	n := countInstructions(code)
+	if n == 0 {
+		return
+	}
+	s.Entries = append(s.Entries, &SourceMapEntry{
+		Start:    s.addr,
+		End:      s.addr + n,
+		File:     c.Meta.fileName,
+		Line:     c.Meta.srcLine,
+		Function: c.Meta.funcName,
+		Command:  c.String(),
+	})
+	s.addr += n
+}
+
+// Append appends the entries of other, which starts at address 0, after the current address.
+func (s *SourceMap) Append(other *SourceMap) {
+	for _, e := range other.Entries {
+		shifted := *e
+		shifted.Start += s.addr
+		shifted.End += s.addr
+		s.Entries = append(s.Entries, &shifted)
+	}
+	s.addr += other.addr
+}
+
+// Lookup returns the entry containing the address, or nil if the address is out of the VM commands.
+func (s *SourceMap) Lookup(addr int) *SourceMapEntry {
+	lo, hi := 0, len(s.Entries)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		e := s.Entries[mid]
+		switch {
+		case addr < e.Start:
+			hi = mid
+		case addr >= e.End:
+			lo = mid + 1
+		default:
+			return e
+		}
+	}
+	return nil
+}
+
+// SourceComment is the comment interleaved before the assembly of the command.
+func SourceComment(c *Command) string {
+	return fmt.Sprintf("// %s:%d: %s", c.Meta.fileName, c.Meta.srcLine, c)
+}
+
+// countInstructions counts lines that occupy ROM, i.e. excluding label declarations and comments.
+func countInstructions(code []string) int {
+	n := 0
+	for _, l := range code {
+		l = strings.TrimSpace(l)
+		if l == "" || strings.HasPrefix(l, "(") || strings.HasPrefix(l, "//") {
+			continue
+		}
+		n++
+	}
+	return n
+}
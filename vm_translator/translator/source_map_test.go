@@ -0,0 +1,50 @@
+package translator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSourceMap(t *testing.T) {
+	meta := &CommandMeta{"Main.vm", "Main.main", 3}
+	push := &Command{Type: CommandPush, Arg1: "local", Arg2: 0, Meta: meta}
+	label := &Command{Type: CommandLabel, Arg1: "LOOP", Meta: &CommandMeta{"Main.vm", "Main.main", 4}}
+	goTo := &Command{Type: CommandGoto, Arg1: "LOOP", Meta: &CommandMeta{"Main.vm", "Main.main", 5}}
+
+	sm := &SourceMap{}
+	sm.Skip([]string{"@256", "D=A", "(Bootstrap$ret.0)"})
+	sm.Add(push, []string{"@LCL", "D=M", "@0", "A=D+A"})
+	sm.Add(label, []string{"(Main.main$LOOP)"})
+	sm.Add(goTo, []string{"@Main.main$LOOP", "0;JMP"})
+
+	want := []*SourceMapEntry{
+		{Start: 2, End: 6, File: "Main.vm", Line: 3, Function: "Main.main", Command: "push local 0"},
+		{Start: 6, End: 8, File: "Main.vm", Line: 5, Function: "Main.main", Command: "goto LOOP"},
+	}
+	if !reflect.DeepEqual(sm.Entries, want) {
+		t.Errorf("SourceMap.Entries = %v, want %v", sm.Entries, want)
+	}
+
+	tests := []struct {
+		name string
+		addr int
+		want *SourceMapEntry
+	}{
+		{name: "bootstrap", addr: 1, want: nil},
+		{name: "first instruction", addr: 2, want: want[0]},
+		{name: "last instruction", addr: 5, want: want[0]},
+		{name: "next command", addr: 7, want: want[1]},
+		{name: "out of range", addr: 8, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sm.Lookup(tt.addr); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SourceMap.Lookup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := SourceComment(push); got != "// Main.vm:3: push local 0" {
+		t.Errorf("SourceComment() = %v", got)
+	}
+}
@@ -0,0 +1,123 @@
+package translator
+
+import (
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Options configures Translate.
+type Options struct {
+	// Bootstrap prepends the code initializing SP and calling Sys.init.
+	Bootstrap bool
+	// Check runs Check over the whole program before translation.
+	Check bool
+	// Comment interleaves the source VM command as a comment before each asm block.
+	Comment bool
+	// SourceMap, if not nil, is filled with the mapping from ROM address to VM command.
+	SourceMap *SourceMap
+}
+
+type fileResult struct {
+	commands  []*Command
+	codes     []string
+	sourceMap *SourceMap
+	err       error
+}
+
+// Translate translates the .vm files, keyed by file name, into assembly lines.
+// Files are parsed and translated concurrently, while the output is always ordered by file name.
+func Translate(files map[string]io.Reader, opts Options) ([]string, error) {
+	var names []string
+	for n := range files {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	results := make([]*fileResult, len(names))
+	parallel(len(names), func(i int) {
+		cmds, err := NewParser(files[names[i]], names[i]).Parse()
+		results[i] = &fileResult{commands: cmds, err: err}
+	})
+
+	var errs ErrorList
+	var commands []*Command
+	for _, r := range results {
+		errs.Add(r.err)
+		commands = append(commands, r.commands...)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if opts.Check {
+		if err := Check(commands); err != nil {
+			return nil, err
+		}
+	}
+
+	parallel(len(results), func(i int) {
+		results[i].translate(opts.Comment)
+	})
+
+	var codes []string
+	sm := &SourceMap{}
+	if opts.Bootstrap {
+		codes = BootstrapLine(NewNamer())
+		sm.Skip(codes)
+	}
+	for _, r := range results {
+		errs.Add(r.err)
+		codes = append(codes, r.codes...)
+		sm.Append(r.sourceMap)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if opts.SourceMap != nil {
+		*opts.SourceMap = *sm
+	}
+
+	return codes, nil
+}
+
+// translate generates the assembly of a file. Symbol counters are per function, so a file can be translated independently.
+func (r *fileResult) translate(comment bool) {
+	var errs ErrorList
+	r.sourceMap = &SourceMap{}
+	namer := NewNamer()
+	for _, c := range r.commands {
+		asm, err := NewAsmCode(c, namer)
+		if err != nil {
+			errs.Add(&SourceError{FileName: c.Meta.fileName, Line: c.Meta.srcLine, Err: err})
+			continue
+		}
+		if asm == nil {
+			continue
+		}
+		if comment {
+			r.codes = append(r.codes, SourceComment(c))
+		}
+		r.sourceMap.Add(c, asm.Code())
+		r.codes = append(r.codes, asm.Code()...)
+	}
+	r.err = errs.Err()
+}
+
+// parallel calls f for 0 to n-1 concurrently, at most GOMAXPROCS calls at a time.
+func parallel(n int, f func(i int)) {
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			f(i)
+		}(i)
+	}
+	wg.Wait()
+}
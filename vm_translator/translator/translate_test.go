@@ -0,0 +1,113 @@
+package translator
+
+import (
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTranslate(t *testing.T) {
+	newFiles := func() map[string]io.Reader {
+		return map[string]io.Reader{
+			"Sys.vm":  strings.NewReader("function Sys.init 0\ncall Main.main 0\nreturn"),
+			"Main.vm": strings.NewReader("function Main.main 0\npush static 1\nreturn"),
+		}
+	}
+
+	sm := &SourceMap{}
+	got, err := Translate(newFiles(), Options{Check: true, Comment: true, SourceMap: sm})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var comments []string
+	for _, l := range got {
+		if strings.HasPrefix(l, "//") {
+			comments = append(comments, l)
+		}
+	}
+	wantComments := []string{
+		"// Main.vm:1: function Main.main 0",
+		"// Main.vm:2: push static 1",
+		"// Main.vm:3: return",
+		"// Sys.vm:1: function Sys.init 0",
+		"// Sys.vm:2: call Main.main 0",
+		"// Sys.vm:3: return",
+	}
+	if !reflect.DeepEqual(comments, wantComments) {
+		t.Errorf("Translate() comments = %v, want %v", comments, wantComments)
+	}
+
+	for i := 0; i < 10; i++ {
+		again, err := Translate(newFiles(), Options{Check: true, Comment: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(again, got) {
+			t.Fatalf("Translate() is not deterministic")
+		}
+	}
+
+	// `function X 0` emits only a label, which occupies no ROM address.
+	if len(sm.Entries) != 4 {
+		t.Fatalf("SourceMap has %d entries, want 4", len(sm.Entries))
+	}
+	if countInstructions(got) != sm.Entries[3].End {
+		t.Errorf("SourceMap ends at %d, want %d", sm.Entries[3].End, countInstructions(got))
+	}
+	for i := 1; i < len(sm.Entries); i++ {
+		if sm.Entries[i].Start != sm.Entries[i-1].End {
+			t.Errorf("SourceMap entry %d starts at %d, want %d", i, sm.Entries[i].Start, sm.Entries[i-1].End)
+		}
+	}
+	if e := sm.Entries[2]; e.File != "Sys.vm" || e.Line != 2 || e.Command != "call Main.main 0" {
+		t.Errorf("SourceMap entry 2 = %v", e)
+	}
+}
+
+func TestTranslate_errors(t *testing.T) {
+	files := map[string]io.Reader{
+		"B.vm": strings.NewReader("function B.f 0\npush local"),
+		"A.vm": strings.NewReader("function A.f 0\nfoo\ncall A.g 0"),
+	}
+	_, err := Translate(files, Options{Check: true})
+	if err == nil {
+		t.Fatal("Translate() error = nil")
+	}
+	want := "A.vm:2: Invalid arithmetic command foo\nB.vm:2: Invalid number of tokens [push local]"
+	if err.Error() != want {
+		t.Errorf("Translate() error = %v, want %v", err, want)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	max := runtime.GOMAXPROCS(0)
+	var mu sync.Mutex
+	running, peak := 0, 0
+	done := make([]bool, 100)
+	parallel(len(done), func(i int) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running--
+		done[i] = true
+		mu.Unlock()
+	})
+	if peak > max {
+		t.Errorf("parallel() ran %d calls at a time, want at most %d", peak, max)
+	}
+	for i, d := range done {
+		if !d {
+			t.Errorf("parallel() did not call f(%d)", i)
+		}
+	}
+}